@@ -2,31 +2,55 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/AlanMute/file-manager/internal/cli"
 	"github.com/AlanMute/file-manager/internal/disk"
 	"github.com/AlanMute/file-manager/internal/filemenu"
 	"github.com/AlanMute/file-manager/internal/jsonmenu"
+	"github.com/AlanMute/file-manager/internal/trashmenu"
 	"github.com/AlanMute/file-manager/internal/xmlmenu"
 	"github.com/AlanMute/file-manager/internal/zipmenu"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/AlanMute/file-manager/pkg/workspace"
 	"github.com/inancgumus/screen"
 )
 
 func main() {
+	rootFlag := flag.String("root", "", "рабочая папка (по умолчанию $"+workspace.EnvRoot+", файл конфигурации или папка документов)")
+	allowOutside := flag.Bool("allow-outside", false, "разрешить пути за пределами рабочей папки")
+	flag.Usage = cli.Usage
+	flag.Parse()
+
+	if err := workspace.Init(*rootFlag); err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка при выборе рабочей папки:", err)
+		os.Exit(1)
+	}
+
+	if flag.NArg() > 0 {
+		os.Exit(cli.Run(flag.Args(), cli.Options{AllowOutside: *allowOutside}))
+	}
+
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
 		screen.Clear()
 		screen.MoveTopLeft()
 
+		rootPath, _ := workspace.Root()
+
 		fmt.Println("\n--- Главное меню ---")
+		fmt.Println("Рабочая папка:", rootPath)
 		fmt.Println("1. Информация о логических дисках")
 		fmt.Println("2. Работа с файлами")
 		fmt.Println("3. Работа с JSON файлами")
 		fmt.Println("4. Работа с XML файлами")
-		fmt.Println("5. Работа с zip архивами")
-		fmt.Println("6. Выход")
+		fmt.Println("5. Работа с архивами (zip, tar)")
+		fmt.Println("6. Корзина")
+		fmt.Println("7. Сменить рабочую папку")
+		fmt.Println("8. Выход")
 
 		fmt.Print("Выберите действие: ")
 		scanner.Scan()
@@ -44,6 +68,10 @@ func main() {
 		case "5":
 			zipmenu.ShowMenu(scanner)
 		case "6":
+			trashmenu.ShowMenu(scanner)
+		case "7":
+			changeRoot(scanner)
+		case "8":
 			fmt.Println("Выход из программы.")
 			os.Exit(0)
 		default:
@@ -51,3 +79,31 @@ func main() {
 		}
 	}
 }
+
+func changeRoot(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Смена рабочей папки ---")
+	fmt.Print("Введите путь к новой рабочей папке: ")
+	scanner.Scan()
+	path := scanner.Text()
+
+	if err := workspace.SetRoot(path); err != nil {
+		fmt.Println("Ошибка при смене рабочей папки:", err)
+		util.Pause()
+		return
+	}
+
+	rootPath, _ := workspace.Root()
+	fmt.Println("Рабочая папка:", rootPath)
+
+	if util.Confirm(scanner, "Сохранить как папку по умолчанию?") {
+		if err := workspace.SaveConfig(workspace.Config{Root: rootPath}); err != nil {
+			fmt.Println("Ошибка при сохранении конфигурации:", err)
+		} else {
+			fmt.Println("Рабочая папка сохранена в конфигурации.")
+		}
+	}
+	util.Pause()
+}
@@ -4,13 +4,15 @@ go 1.22.4
 
 require (
 	github.com/inancgumus/screen v0.0.0-20190314163918-06e984b86ed3
+	github.com/klauspost/compress v1.18.0
 	github.com/shirou/gopsutil v3.21.11+incompatible
+	github.com/ulikunitz/xz v0.5.9
+	golang.org/x/crypto v0.28.0
+	golang.org/x/term v0.25.0
 )
 
 require (
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/crypto v0.28.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/term v0.25.0 // indirect
 )
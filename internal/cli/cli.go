@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/AlanMute/file-manager/pkg/workspace"
+	"golang.org/x/term"
+)
+
+var errUsage = errors.New("неверные аргументы")
+
+// Options — общие флаги командной строки; их разбирает main вместе с
+// -root и передаёт в Run.
+type Options struct {
+	// AllowOutside разрешает пути за пределами рабочей папки.
+	AllowOutside bool
+}
+
+var runOptions Options
+
+// runCtx отменяется по Ctrl+C, чтобы долгие команды успели убрать за
+// собой недописанные файлы.
+var runCtx = context.Background()
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+type group struct {
+	name     string
+	commands map[string]command
+}
+
+var groups []group
+
+func init() {
+	groups = []group{
+		{"file", fileCommands},
+		{"json", jsonCommands},
+		{"xml", xmlCommands},
+		{"zip", zipCommands},
+		{"trash", trashCommands},
+		{"disk", diskCommands},
+	}
+}
+
+// Run выполняет подкоманду и возвращает код выхода:
+// 0 — успех, 1 — ошибка выполнения, 2 — неверные аргументы,
+// 130 — команда прервана по Ctrl+C.
+func Run(args []string, opts Options) int {
+	runOptions = opts
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	g, ok := findGroup(args[0])
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Неизвестная команда:", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+	if len(args) < 2 {
+		printGroupUsage(os.Stderr, g)
+		return 2
+	}
+
+	cmd, ok := g.commands[args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Неизвестная команда: %s %s\n", g.name, args[1])
+		printGroupUsage(os.Stderr, g)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	runCtx = ctx
+
+	err := cmd.run(args[2:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, "Прервано, недописанные файлы удалены.")
+		return 130
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprintf(os.Stdout, "Использование: file-manager %s %s %s\n", g.name, args[1], cmd.usage)
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "Использование: file-manager %s %s %s\n", g.name, args[1], cmd.usage)
+		return 2
+	default:
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		return 1
+	}
+}
+
+func findGroup(name string) (group, bool) {
+	for _, g := range groups {
+		if g.name == name {
+			return g, true
+		}
+	}
+	return group{}, false
+}
+
+// Usage выводит справку по командам; используется как flag.Usage.
+func Usage() {
+	printUsage(os.Stderr)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: file-manager [-root ПАПКА] [-allow-outside] [команда] [подкоманда] [флаги] [аргументы]")
+	fmt.Fprintln(w, "Без аргументов запускается интерактивное меню.")
+	fmt.Fprintln(w, "Рабочая папка берётся из флага -root, переменной FILE_MANAGER_ROOT,")
+	fmt.Fprintln(w, "файла конфигурации или папки документов пользователя.")
+	fmt.Fprintln(w, "Пути за пределами рабочей папки отклоняются, если не указан -allow-outside.")
+	fmt.Fprintln(w)
+	for _, g := range groups {
+		printGroupUsage(w, g)
+	}
+}
+
+func printGroupUsage(w io.Writer, g group) {
+	names := make([]string, 0, len(g.commands))
+	for name := range g.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  file-manager %s %s %s\n", g.name, name, g.commands[name].usage)
+	}
+}
+
+// progress возвращает индикатор прогресса в stderr или nil, если stderr
+// не терминал и индикатор только засорил бы перенаправленный вывод.
+func progress() func(fsops.ProgressInfo) {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return util.NewProgressBar(os.Stderr).Update
+}
+
+// parseFlags разбирает флаги подкоманды и проверяет число позиционных аргументов.
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, err)
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		return errUsage
+	}
+	return nil
+}
+
+// stringList — флаг, который можно указать несколько раз.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseSize разбирает размер в байтах с необязательным суффиксом K, M, G или T.
+func parseSize(s string) (int64, error) {
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		switch strings.ToUpper(s[n-1:]) {
+		case "K":
+			multiplier = 1 << 10
+		case "M":
+			multiplier = 1 << 20
+		case "G":
+			multiplier = 1 << 30
+		case "T":
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:n-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("неверный размер: %s", s)
+	}
+	return n * multiplier, nil
+}
+
+func argOr(fs *flag.FlagSet, i int, def string) string {
+	if fs.NArg() > i {
+		return fs.Arg(i)
+	}
+	return def
+}
+
+// resolve возвращает путь к name в рабочей папке. Пути за её пределами
+// допускаются только с флагом -allow-outside.
+func resolve(name string) (string, error) {
+	path, err := workspace.Path(name)
+
+	var outside *workspace.OutsideRootError
+	if errors.As(err, &outside) && runOptions.AllowOutside {
+		return outside.Path, nil
+	}
+	return path, err
+}
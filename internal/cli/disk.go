@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/AlanMute/file-manager/internal/disk"
+)
+
+var diskCommands = map[string]command{
+	"info": {"", diskInfo},
+}
+
+func diskInfo(args []string) error {
+	fs := flag.NewFlagSet("disk info", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	return disk.PrintInfo(os.Stdout)
+}
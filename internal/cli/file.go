@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlanMute/file-manager/internal/filemenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+var fileCommands = map[string]command{
+	"create": {"NAME", fileCreate},
+	"write":  {"NAME [TEXT...]  (без TEXT строки читаются из stdin)", fileWrite},
+	"read":   {"NAME", fileRead},
+	"delete": {"[-permanent] NAME", fileDelete},
+	"list":   {"[DIR]", fileList},
+	"mkdir":  {"DIR", fileMkdir},
+	"rmdir":  {"[-r] [-permanent] DIR", fileRmdir},
+	"tree":   {"[-depth N] [DIR]", fileTree},
+	"copy":   {"[-conflict fail|overwrite|skip|rename] SRC DST", fileCopy},
+	"move":   {"[-conflict fail|overwrite|skip|rename] SRC DST", fileMove},
+	"rename": {"[-conflict fail|overwrite|skip|rename] PATH NEWNAME", fileRename},
+}
+
+func fileCreate(args []string) error {
+	fs := flag.NewFlagSet("file create", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := fsops.CreateFile(fullPath); err != nil {
+		return err
+	}
+	fmt.Println("Файл создан по пути:", fullPath)
+	return nil
+}
+
+func fileWrite(args []string) error {
+	fs := flag.NewFlagSet("file write", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args()[1:], " ") + "\n"
+	if fs.NArg() == 1 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+
+	fullPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := fsops.AppendFile(fullPath, text); err != nil {
+		return err
+	}
+	fmt.Println("Строка записана в файл по пути:", fullPath)
+	return nil
+}
+
+func fileRead(args []string) error {
+	fs := flag.NewFlagSet("file read", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	data, err := fsops.ReadFile(fullPath)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func fileDelete(args []string) error {
+	fs := flag.NewFlagSet("file delete", flag.ContinueOnError)
+	permanent := fs.Bool("permanent", false, "удалить окончательно, минуя корзину")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return remove(fullPath, *permanent)
+}
+
+func fileList(args []string) error {
+	fs := flag.NewFlagSet("file list", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	dirPath, err := resolve(argOr(fs, 0, "."))
+	if err != nil {
+		return err
+	}
+	return filemenu.PrintDir(os.Stdout, dirPath)
+}
+
+func fileMkdir(args []string) error {
+	fs := flag.NewFlagSet("file mkdir", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	dirPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := fsops.Mkdir(dirPath); err != nil {
+		return err
+	}
+	fmt.Println("Папка создана по пути:", dirPath)
+	return nil
+}
+
+func fileRmdir(args []string) error {
+	fs := flag.NewFlagSet("file rmdir", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "удалить вместе с содержимым (в корзину, если не указан -permanent)")
+	permanent := fs.Bool("permanent", false, "удалить окончательно, минуя корзину")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	dirPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *recursive && !*permanent {
+		return remove(dirPath, false)
+	}
+	if err := fsops.Rmdir(dirPath, *recursive); err != nil {
+		return err
+	}
+	fmt.Println("Папка удалена по пути:", dirPath)
+	return nil
+}
+
+func fileTree(args []string) error {
+	fs := flag.NewFlagSet("file tree", flag.ContinueOnError)
+	depth := fs.Int("depth", 0, "максимальная глубина (0 — без ограничения)")
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	dirPath, err := resolve(argOr(fs, 0, "."))
+	if err != nil {
+		return err
+	}
+	return filemenu.PrintTree(os.Stdout, dirPath, *depth)
+}
+
+func fileCopy(args []string) error {
+	return fileTransfer("file copy", args, fsops.Copy)
+}
+
+func fileMove(args []string) error {
+	return fileTransfer("file move", args, fsops.Move)
+}
+
+func fileTransfer(name string, args []string, transfer func(src, dst string, opts fsops.TransferOptions) error) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	conflict := fs.String("conflict", "fail", "действие при существующем файле: fail, overwrite, skip, rename")
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	opts, err := transferOptions(*conflict)
+	if err != nil {
+		return err
+	}
+
+	srcPath, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	dstPath, err := resolve(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	dstPath = fsops.IntoDir(srcPath, dstPath)
+
+	if err := transfer(srcPath, dstPath, opts); err != nil {
+		return err
+	}
+	fmt.Println(srcPath, "->", dstPath)
+	return nil
+}
+
+func fileRename(args []string) error {
+	fs := flag.NewFlagSet("file rename", flag.ContinueOnError)
+	conflict := fs.String("conflict", "fail", "действие при существующем файле: fail, overwrite, skip, rename")
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	opts, err := transferOptions(*conflict)
+	if err != nil {
+		return err
+	}
+
+	path, err := resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := fsops.Rename(path, fs.Arg(1), opts); err != nil {
+		return err
+	}
+	fmt.Println(path, "->", fs.Arg(1))
+	return nil
+}
+
+func transferOptions(conflict string) (fsops.TransferOptions, error) {
+	actions := map[string]fsops.ConflictAction{
+		"fail":      fsops.ConflictFail,
+		"overwrite": fsops.ConflictOverwrite,
+		"skip":      fsops.ConflictSkip,
+		"rename":    fsops.ConflictRename,
+	}
+
+	action, ok := actions[conflict]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Неизвестное значение -conflict:", conflict)
+		return fsops.TransferOptions{}, errUsage
+	}
+	return fsops.TransferOptions{
+		OnConflict: func(src, dst string) fsops.ConflictAction {
+			if action != fsops.ConflictFail {
+				fmt.Fprintf(os.Stderr, "Конфликт: %s уже существует (%s)\n", dst, conflict)
+			}
+			return action
+		},
+		Ctx:      runCtx,
+		Progress: progress(),
+	}, nil
+}
@@ -0,0 +1,422 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/AlanMute/file-manager/internal/jsonmenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/util"
+)
+
+var jsonCommands = map[string]command{
+	"create":    {"NAME [ПУТЬ[:ТИП]=ЗНАЧЕНИЕ...]", jsonCreate},
+	"serialize": {"[-name ИМЯ] [-age ВОЗРАСТ] [-email EMAIL] NAME", jsonSerialize},
+	"read":      {"NAME", jsonRead},
+	"get":       {"NAME [ПУТЬ]", jsonGet},
+	"set":       {"NAME ПУТЬ[:ТИП]=ЗНАЧЕНИЕ...", jsonSet},
+	"del":       {"NAME ПУТЬ...", jsonDel},
+	"rename":    {"NAME ПУТЬ НОВЫЙ_КЛЮЧ", jsonRename},
+	"append":    {"[-type ТИП] NAME ПУТЬ ЗНАЧЕНИЕ...", jsonAppend},
+	"query":     {"[-paths | -values] ВЫРАЖЕНИЕ [NAME | -]", jsonQuery},
+	"validate":  {"NAME SCHEMA", jsonValidate},
+	"format":    {"[-check] [-indent N|tab] [-minify] [-sort] [-w] NAME | -", jsonFormat},
+	"delete":    {"[-permanent] NAME", jsonDelete},
+}
+
+func jsonCreate(args []string) error {
+	fs := flag.NewFlagSet("json create", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	doc, err := setValues(nil, fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		doc = jsondoc.NewObject()
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".json")
+	if err != nil {
+		return err
+	}
+	if err := jsondoc.WriteFile(fullPath, doc, jsondoc.DefaultStyle); err != nil {
+		return err
+	}
+	fmt.Println("JSON файл создан по пути:", fullPath)
+	return nil
+}
+
+// setValues записывает в документ значения из аргументов ПУТЬ=ЗНАЧЕНИЕ
+// в порядке их следования и возвращает новый корень. Тип значения
+// определяется по тексту или указывается после пути через двоеточие:
+// port:string=8080.
+func setValues(doc *jsondoc.Value, args []string) (*jsondoc.Value, error) {
+	for _, arg := range args {
+		key, text, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			fmt.Fprintln(os.Stderr, "Ожидалась пара ПУТЬ=ЗНАЧЕНИЕ:", arg)
+			return nil, errUsage
+		}
+
+		typ := jsondoc.TypeAuto
+		if i := strings.LastIndexByte(key, ':'); i >= 0 && slices.Contains(jsondoc.Types, key[i+1:]) {
+			key, typ = key[:i], key[i+1:]
+		}
+		path, err := jsondoc.ParsePath(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsondoc.ParseScalar(text, typ)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if doc, err = jsondoc.Set(doc, path, value); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func jsonSerialize(args []string) error {
+	var person jsonmenu.Person
+	fs := flag.NewFlagSet("json serialize", flag.ContinueOnError)
+	fs.StringVar(&person.Name, "name", "", "имя")
+	fs.IntVar(&person.Age, "age", 0, "возраст")
+	fs.StringVar(&person.Email, "email", "", "email")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".json")
+	if err != nil {
+		return err
+	}
+	if err := fsops.WriteJSON(fullPath, person); err != nil {
+		return err
+	}
+	fmt.Println("Объект сериализован в JSON и записан по пути:", fullPath)
+	return nil
+}
+
+func jsonRead(args []string) error {
+	fs := flag.NewFlagSet("json read", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".json")
+	if err != nil {
+		return err
+	}
+	data, err := fsops.ReadFile(fullPath)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func jsonDelete(args []string) error {
+	fs := flag.NewFlagSet("json delete", flag.ContinueOnError)
+	permanent := fs.Bool("permanent", false, "удалить окончательно, минуя корзину")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".json")
+	if err != nil {
+		return err
+	}
+	return remove(fullPath, *permanent)
+}
+
+// loadJSON читает документ NAME.json из рабочей папки.
+func loadJSON(name string) (string, *jsondoc.Value, jsondoc.Style, error) {
+	fullPath, err := resolve(name + ".json")
+	if err != nil {
+		return "", nil, jsondoc.Style{}, err
+	}
+	doc, style, err := jsondoc.ReadFile(fullPath)
+	return fullPath, doc, style, err
+}
+
+func jsonGet(args []string) error {
+	fs := flag.NewFlagSet("json get", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+
+	path, err := jsondoc.ParsePath(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	_, doc, style, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	value, err := jsondoc.Get(doc, path)
+	if err != nil {
+		return err
+	}
+
+	// Строки выводятся без кавычек, чтобы их было удобно подставлять в
+	// другие команды.
+	if value.Kind == jsondoc.String {
+		fmt.Println(value.Str)
+		return nil
+	}
+	indent := style.Indent
+	if indent == "" {
+		indent = jsondoc.DefaultStyle.Indent
+	}
+	fmt.Println(value.Text(indent))
+	return nil
+}
+
+func jsonSet(args []string) error {
+	fs := flag.NewFlagSet("json set", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	fullPath, doc, style, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if doc, err = setValues(doc, fs.Args()[1:]); err != nil {
+		return err
+	}
+	if err := jsondoc.WriteFile(fullPath, doc, style); err != nil {
+		return err
+	}
+	fmt.Println("Изменения сохранены в", fullPath)
+	return nil
+}
+
+func jsonDel(args []string) error {
+	fs := flag.NewFlagSet("json del", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	fullPath, doc, style, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, arg := range fs.Args()[1:] {
+		path, err := jsondoc.ParsePath(arg)
+		if err != nil {
+			return err
+		}
+		if _, err := jsondoc.Delete(doc, path); err != nil {
+			return err
+		}
+	}
+	if err := jsondoc.WriteFile(fullPath, doc, style); err != nil {
+		return err
+	}
+	fmt.Println("Изменения сохранены в", fullPath)
+	return nil
+}
+
+func jsonRename(args []string) error {
+	fs := flag.NewFlagSet("json rename", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 3, 3); err != nil {
+		return err
+	}
+
+	path, err := jsondoc.ParsePath(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	fullPath, doc, style, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := jsondoc.Rename(doc, path, fs.Arg(2)); err != nil {
+		return err
+	}
+	if err := jsondoc.WriteFile(fullPath, doc, style); err != nil {
+		return err
+	}
+	fmt.Println("Изменения сохранены в", fullPath)
+	return nil
+}
+
+func jsonAppend(args []string) error {
+	fs := flag.NewFlagSet("json append", flag.ContinueOnError)
+	typ := fs.String("type", jsondoc.TypeAuto, "тип значений: "+strings.Join(jsondoc.Types, ", "))
+	if err := parseFlags(fs, args, 3, -1); err != nil {
+		return err
+	}
+
+	path, err := jsondoc.ParsePath(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	var values []*jsondoc.Value
+	for _, text := range fs.Args()[2:] {
+		value, err := jsondoc.ParseScalar(text, *typ)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+
+	fullPath, doc, style, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := jsondoc.Append(doc, path, values...); err != nil {
+		return err
+	}
+	if err := jsondoc.WriteFile(fullPath, doc, style); err != nil {
+		return err
+	}
+	fmt.Println("Изменения сохранены в", fullPath)
+	return nil
+}
+
+func jsonQuery(args []string) error {
+	fs := flag.NewFlagSet("json query", flag.ContinueOnError)
+	paths := fs.Bool("paths", false, "выводить только пути")
+	values := fs.Bool("values", false, "выводить только значения, строки без кавычек")
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+	if *paths && *values {
+		return errUsage
+	}
+
+	query, err := jsondoc.CompileQuery(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var doc *jsondoc.Value
+	if name := fs.Arg(1); name == "" || name == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		if doc, err = jsondoc.Parse(data); err != nil {
+			return &fsops.Error{Op: "разбор JSON", Path: "stdin", Err: err}
+		}
+	} else if _, doc, _, err = loadJSON(name); err != nil {
+		return err
+	}
+
+	matches := query.Select(doc)
+	if len(matches) == 0 {
+		fmt.Fprintln(os.Stderr, "Совпадений не найдено.")
+		return nil
+	}
+	w := bufio.NewWriter(os.Stdout)
+	jsonmenu.WriteMatches(w, matches, *paths, *values)
+	return w.Flush()
+}
+
+func jsonValidate(args []string) error {
+	fs := flag.NewFlagSet("json validate", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	schemaPath, schemaDoc, _, err := loadJSON(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	schema, err := jsondoc.CompileSchema(schemaDoc)
+	if err != nil {
+		return &fsops.Error{Op: "чтение схемы", Path: schemaPath, Err: err}
+	}
+	_, doc, _, err := loadJSON(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	violations := schema.Validate(doc)
+	jsonmenu.PrintViolations(os.Stdout, violations)
+	if len(violations) > 0 {
+		return jsondoc.ErrSchemaMismatch
+	}
+	return nil
+}
+
+func jsonFormat(args []string) error {
+	fs := flag.NewFlagSet("json format", flag.ContinueOnError)
+	check := fs.Bool("check", false, "только проверить синтаксис")
+	indentFlag := fs.String("indent", "2", "отступ: число пробелов или tab")
+	minify := fs.Bool("minify", false, "записать в одну строку")
+	sortKeys := fs.Bool("sort", false, "отсортировать ключи объектов")
+	write := fs.Bool("w", false, "перезаписать файл вместо вывода")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	indent, err := jsondoc.ParseIndent(*indentFlag)
+	if err != nil {
+		return err
+	}
+	if *minify {
+		indent = ""
+	}
+
+	var fullPath, source string
+	var data []byte
+	if name := fs.Arg(0); name == "-" {
+		if *write {
+			return errUsage
+		}
+		source = "stdin"
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return err
+		}
+	} else {
+		if fullPath, err = resolve(name + ".json"); err != nil {
+			return err
+		}
+		source = fullPath
+		if data, err = fsops.ReadFile(fullPath); err != nil {
+			return err
+		}
+	}
+
+	doc, err := jsondoc.Parse(data)
+	if err != nil {
+		return &fsops.Error{Op: "разбор JSON", Path: source, Err: fmt.Errorf("%w\n%s", err, strings.TrimSuffix(jsonmenu.ErrorContext(data, err), "\n"))}
+	}
+	if *check {
+		fmt.Println("Синтаксис JSON корректен:", source)
+		return nil
+	}
+
+	if *sortKeys {
+		jsondoc.SortKeys(doc)
+	}
+	if *write {
+		if err := jsondoc.WriteFile(fullPath, doc, jsondoc.Style{Indent: indent, FinalNewline: true}); err != nil {
+			return err
+		}
+		fmt.Println("Файл отформатирован:", fullPath)
+		return nil
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if util.ColorEnabled(os.Stdout) {
+		doc.EncodeColor(w, indent)
+	} else {
+		doc.Encode(w, indent)
+	}
+	fmt.Fprintln(w)
+	return w.Flush()
+}
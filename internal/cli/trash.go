@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlanMute/file-manager/internal/trashmenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
+)
+
+var trashCommands = map[string]command{
+	"list":    {"", trashList},
+	"restore": {"[-conflict fail|overwrite|skip|rename] NAME", trashRestore},
+	"empty":   {"[-older-than ВОЗРАСТ]  (например 30d или 12h)", trashEmpty},
+}
+
+func trashList(args []string) error {
+	fs := flag.NewFlagSet("trash list", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	items, err := trash.List()
+	if err != nil {
+		return err
+	}
+	return trashmenu.PrintItems(os.Stdout, items)
+}
+
+func trashRestore(args []string) error {
+	fs := flag.NewFlagSet("trash restore", flag.ContinueOnError)
+	conflict := fs.String("conflict", "fail", "действие при существующем файле: fail, overwrite, skip, rename")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	opts, err := transferOptions(*conflict)
+	if err != nil {
+		return err
+	}
+
+	restored, err := trash.Restore(fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	if restored {
+		fmt.Println("Файл восстановлен:", fs.Arg(0))
+	} else {
+		fmt.Println("Файл пропущен и остался в корзине:", fs.Arg(0))
+	}
+	return nil
+}
+
+func trashEmpty(args []string) error {
+	fs := flag.NewFlagSet("trash empty", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "", "удалить только файлы, лежащие в корзине дольше указанного времени")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	var age time.Duration
+	if *olderThan != "" {
+		var err error
+		if age, err = parseAge(*olderThan); err != nil {
+			fmt.Fprintln(os.Stderr, "Неверное значение -older-than:", *olderThan)
+			return errUsage
+		}
+	}
+
+	removed, err := trash.Empty(age)
+	fmt.Println("Удалено файлов:", removed)
+	return err
+}
+
+// remove перемещает path в корзину или, если permanent, удаляет его.
+func remove(path string, permanent bool) error {
+	if permanent {
+		if err := fsops.Remove(path); err != nil {
+			return err
+		}
+		fmt.Println("Удалено:", path)
+		return nil
+	}
+
+	if err := trash.Put(path); err != nil {
+		return err
+	}
+	fmt.Println("Перемещено в корзину:", path)
+	return nil
+}
+
+// parseAge понимает как time.ParseDuration, так и дни: "30d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("неверное число дней: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+var xmlCommands = map[string]command{
+	"create": {"NAME [ТЕГ=ЗНАЧЕНИЕ...]", xmlCreate},
+	"read":   {"NAME", xmlRead},
+	"delete": {"[-permanent] NAME", xmlDelete},
+}
+
+func xmlCreate(args []string) error {
+	fs := flag.NewFlagSet("xml create", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	data, err := parsePairs(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".xml")
+	if err != nil {
+		return err
+	}
+	if err := fsops.WriteXML(fullPath, data); err != nil {
+		return err
+	}
+	fmt.Println("XML файл создан по пути:", fullPath)
+	return nil
+}
+
+func xmlRead(args []string) error {
+	fs := flag.NewFlagSet("xml read", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".xml")
+	if err != nil {
+		return err
+	}
+	data, err := fsops.ReadFile(fullPath)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func xmlDelete(args []string) error {
+	fs := flag.NewFlagSet("xml delete", flag.ContinueOnError)
+	permanent := fs.Bool("permanent", false, "удалить окончательно, минуя корзину")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	fullPath, err := resolve(fs.Arg(0) + ".xml")
+	if err != nil {
+		return err
+	}
+	return remove(fullPath, *permanent)
+}
+
+// parsePairs разбирает аргументы вида КЛЮЧ=ЗНАЧЕНИЕ.
+func parsePairs(args []string) (map[string]string, error) {
+	data := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			fmt.Fprintln(os.Stderr, "Ожидалась пара КЛЮЧ=ЗНАЧЕНИЕ:", arg)
+			return nil, errUsage
+		}
+		data[key] = value
+	}
+	return data, nil
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/AlanMute/file-manager/internal/zipmenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+)
+
+var zipCommands = map[string]command{
+	"create":  {"ARCHIVE", zipCreate},
+	"add":     {"[-include GLOB]... [-exclude GLOB]... [-symlinks store|follow|skip] [-dir DIR] [-compression METHOD[:LEVEL]] [-rule GLOB=METHOD[:LEVEL]]... [-workers N] [-encrypt] ARCHIVE PATH...", zipAdd},
+	"list":    {"[-sort name|size|compressed|ratio|method|crc|modified|mode] [-desc] [-format table|json|csv] ARCHIVE", zipList},
+	"extract": {"[-dir DEST] [-pattern GLOB]... [-max-size 4G] [-max-entries N] [-max-ratio N] [-conflict fail|overwrite|skip|rename] ARCHIVE [ENTRY...]", zipExtract},
+	"delete":  {"[-permanent] ARCHIVE", zipDelete},
+	"comment": {"[-set TEXT] ARCHIVE [ENTRY]  (без -set комментарий выводится)", zipComment},
+	"touch":   {"[-time 'ГГГГ-ММ-ДД ЧЧ:ММ:СС'|now] ARCHIVE ENTRY|GLOB...", zipTouch},
+	"info":    {"ARCHIVE ENTRY", zipInfo},
+	"ls":      {"ARCHIVE [DIR]", zipLs},
+	"tree":    {"[-depth N] ARCHIVE [DIR]", zipTree},
+	"cat":     {"ARCHIVE ENTRY...", zipCat},
+	"get":     {"[-conflict fail|overwrite|skip|rename] ARCHIVE ENTRY|DIR [DEST]", zipGet},
+	"rm":      {"ARCHIVE ENTRY|GLOB...", zipRemove},
+	"mv":      {"ARCHIVE OLD NEW", zipRename},
+	"test":    {"ARCHIVE", zipTest},
+}
+
+func zipCreate(args []string) error {
+	fs := flag.NewFlagSet("zip create", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	archivePath, err := resolve(fsops.ArchiveName(fs.Arg(0)))
+	if err != nil {
+		return err
+	}
+	archive, err := fsops.CreateArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Архив %s создан по пути: %s\n", archive.Format(), archivePath)
+	return nil
+}
+
+func zipAdd(args []string) error {
+	var opts fsops.AddOptions
+	fs := flag.NewFlagSet("zip add", flag.ContinueOnError)
+	fs.Var((*stringList)(&opts.Include), "include", "добавлять только файлы по шаблону (можно указать несколько раз)")
+	fs.Var((*stringList)(&opts.Exclude), "exclude", "не добавлять файлы по шаблону (можно указать несколько раз)")
+	symlinks := fs.String("symlinks", "store", "символические ссылки: store, follow, skip")
+	fs.StringVar(&opts.Dir, "dir", "", "папка внутри архива, куда добавить файлы")
+	encrypt := fs.Bool("encrypt", false, "зашифровать новые записи zip паролем (AES-256)")
+	compression := fs.String("compression", "", "сжатие новых записей: store, deflate или zstd с уровнем 0–9, например deflate:9")
+	var rules stringList
+	fs.Var(&rules, "rule", "сжатие для файлов по шаблону, например *.log=zstd:9 (можно указать несколько раз)")
+	fs.IntVar(&opts.Workers, "workers", 0, "сколько файлов сжимать одновременно, 0 — по числу процессоров")
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	if *compression != "" {
+		c, err := fsops.ParseCompression(*compression)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return errUsage
+		}
+		opts.Compression = &c
+	}
+	for _, text := range rules {
+		rule, err := fsops.ParseCompressionRule(text)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return errUsage
+		}
+		opts.Rules = append(opts.Rules, rule)
+	}
+
+	switch *symlinks {
+	case "store":
+		opts.Symlinks = fsops.SymlinkStore
+	case "follow":
+		opts.Symlinks = fsops.SymlinkFollow
+	case "skip":
+		opts.Symlinks = fsops.SymlinkSkip
+	default:
+		fmt.Fprintln(os.Stderr, "Неизвестное значение -symlinks:", *symlinks)
+		return errUsage
+	}
+
+	archive, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *encrypt {
+		if opts.Password, err = util.AskNewPassword(bufio.NewScanner(os.Stdin)); err != nil {
+			return err
+		}
+	}
+
+	var paths []string
+	for _, name := range fs.Args()[1:] {
+		path, err := resolve(name)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	opts.Ctx, opts.Progress = runCtx, progress()
+	summary, err := archive.Add(paths, opts)
+	if err != nil {
+		return err
+	}
+	zipmenu.PrintAddSummary(os.Stdout, summary)
+	return nil
+}
+
+func zipList(args []string) error {
+	fs := flag.NewFlagSet("zip list", flag.ContinueOnError)
+	column := fs.String("sort", "", "столбец для сортировки (по умолчанию порядок в архиве)")
+	desc := fs.Bool("desc", false, "сортировать по убыванию")
+	format := fs.String("format", "table", "формат вывода: table, json, csv (без итоговой строки)")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	archive, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	entries, err := archive.List()
+	if err != nil {
+		return err
+	}
+
+	if *column != "" {
+		if err := zipmenu.SortListing(entries, *column, *desc); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return errUsage
+		}
+	}
+	return zipmenu.PrintListing(os.Stdout, entries, *format)
+}
+
+func zipExtract(args []string) error {
+	opts := fsops.ExtractOptions{Limits: fsops.DefaultExtractLimits}
+	fs := flag.NewFlagSet("zip extract", flag.ContinueOnError)
+	dir := fs.String("dir", "", "папка назначения (по умолчанию папка с именем архива, а при указании ENTRY — текущая)")
+	fs.Var((*stringList)(&opts.Patterns), "pattern", "распаковать только записи по шаблону (можно указать несколько раз)")
+	maxSize := fs.String("max-size", strconv.FormatInt(opts.Limits.MaxTotalSize, 10), "максимальный общий размер распакованных данных, 0 — без ограничения")
+	fs.IntVar(&opts.Limits.MaxEntries, "max-entries", opts.Limits.MaxEntries, "максимальное число записей, 0 — без ограничения")
+	fs.Float64Var(&opts.Limits.MaxRatio, "max-ratio", opts.Limits.MaxRatio, "максимальная степень сжатия записи, 0 — без ограничения")
+	conflict := fs.String("conflict", "fail", "действие при существующем файле: fail, overwrite, skip, rename")
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	var err error
+	if opts.Limits.MaxTotalSize, err = parseSize(*maxSize); err != nil {
+		fmt.Fprintln(os.Stderr, "Неверное значение -max-size:", *maxSize)
+		return errUsage
+	}
+	transfer, err := transferOptions(*conflict)
+	if err != nil {
+		return err
+	}
+	opts.OnConflict = transfer.OnConflict
+	opts.Patterns = append(opts.Patterns, fs.Args()[1:]...)
+
+	archive, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		*dir = fsops.TrimArchiveExt(archive.Path())
+		if fs.NArg() > 1 {
+			*dir = "."
+		}
+	}
+	destDir, err := resolve(*dir)
+	if err != nil {
+		return err
+	}
+
+	if opts.Password, err = askPassword(archive); err != nil {
+		return err
+	}
+
+	opts.Ctx, opts.Progress = runCtx, progress()
+	summary, err := archive.Extract(destDir, opts)
+	if errors.Is(err, context.Canceled) || errors.Is(err, fsops.ErrEntryNotFound) {
+		return err
+	}
+	zipmenu.PrintExtractSummary(os.Stdout, summary)
+	return err
+}
+
+func zipDelete(args []string) error {
+	fs := flag.NewFlagSet("zip delete", flag.ContinueOnError)
+	permanent := fs.Bool("permanent", false, "удалить окончательно, минуя корзину")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	archivePath, err := resolve(fsops.ArchiveName(fs.Arg(0)))
+	if err != nil {
+		return err
+	}
+	return remove(archivePath, *permanent)
+}
+
+func zipRemove(args []string) error {
+	fs := flag.NewFlagSet("zip rm", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	archivePath, err := zipPath(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	removed, err := fsops.RemoveFromZip(archivePath, fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	for _, name := range removed {
+		fmt.Println("-", name)
+	}
+	return nil
+}
+
+func zipRename(args []string) error {
+	fs := flag.NewFlagSet("zip mv", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 3, 3); err != nil {
+		return err
+	}
+
+	archivePath, err := zipPath(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	renamed, err := fsops.RenameInZip(archivePath, fs.Arg(1), fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	for _, line := range renamed {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func zipTest(args []string) error {
+	fs := flag.NewFlagSet("zip test", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	archive, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	password, err := askPassword(archive)
+	if err != nil {
+		return err
+	}
+	report, err := archive.Test(password)
+	if err != nil {
+		return err
+	}
+	zipmenu.PrintTestReport(os.Stdout, report)
+	if !report.OK() {
+		return fmt.Errorf("архив %s повреждён", archive.Path())
+	}
+	return nil
+}
+
+// openArchive находит архив в рабочей папке. Если файла с таким именем
+// нет, к имени дописывается ".zip"; формат определяется по содержимому.
+func openArchive(name string) (fsops.Archive, error) {
+	archivePath, err := resolve(name)
+	if info, statErr := os.Stat(archivePath); err != nil || statErr != nil || !info.Mode().IsRegular() {
+		archivePath, err = resolve(fsops.ArchiveName(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fsops.OpenArchive(archivePath)
+}
+
+// askPassword спрашивает пароль, если в архиве есть зашифрованные записи.
+func askPassword(archive fsops.Archive) (string, error) {
+	entries, err := archive.List()
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Encryption != "" {
+			return util.AskPassword(bufio.NewScanner(os.Stdin), "Пароль архива: "), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlanMute/file-manager/internal/filemenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+func zipLs(args []string) error {
+	fs := flag.NewFlagSet("zip ls", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+
+	fsys, err := openZipFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	infos, err := fsops.ListDirFS(fsys, entryName(argOr(fs, 1, ".")))
+	if err != nil {
+		return err
+	}
+	return filemenu.PrintInfos(os.Stdout, infos)
+}
+
+func zipTree(args []string) error {
+	fs := flag.NewFlagSet("zip tree", flag.ContinueOnError)
+	depth := fs.Int("depth", 0, "максимальная глубина, 0 — без ограничения")
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+
+	fsys, err := openZipFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	dir := entryName(argOr(fs, 1, "."))
+	tree, err := fsops.TreeFS(fsys, dir, *depth)
+	if err != nil {
+		return err
+	}
+	filemenu.PrintTreeNode(os.Stdout, "/"+strings.TrimPrefix(dir, "."), tree)
+	return nil
+}
+
+func zipCat(args []string) error {
+	fs := flag.NewFlagSet("zip cat", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	fsys, err := openZipFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	for _, name := range fs.Args()[1:] {
+		file, err := fsys.Open(entryName(name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(os.Stdout, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func zipGet(args []string) error {
+	fs := flag.NewFlagSet("zip get", flag.ContinueOnError)
+	conflict := fs.String("conflict", "fail", "действие при существующем файле: fail, overwrite, skip, rename")
+	if err := parseFlags(fs, args, 2, 3); err != nil {
+		return err
+	}
+	opts, err := transferOptions(*conflict)
+	if err != nil {
+		return err
+	}
+	opts.Limits = fsops.DefaultExtractLimits
+
+	fsys, err := openZipFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	name := entryName(fs.Arg(1))
+	dstPath, err := resolve(argOr(fs, 2, "."))
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dstPath); err == nil && info.IsDir() {
+		base := path.Base(name)
+		if name == "." {
+			base = fsops.TrimArchiveExt(filepath.Base(fs.Arg(0)))
+		}
+		dstPath = filepath.Join(dstPath, base)
+	}
+
+	if err := fsops.CopyFromFS(fsys, name, dstPath, opts); err != nil {
+		return err
+	}
+	fmt.Println(name, "->", dstPath)
+	return nil
+}
+
+// openZipFS открывает zip архив как файловую систему, спрашивая пароль,
+// если в архиве есть зашифрованные записи.
+func openZipFS(name string) (*fsops.ZipFS, error) {
+	archivePath, err := zipPath(name)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := fsops.OpenArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	password, err := askPassword(archive)
+	if err != nil {
+		return nil, err
+	}
+	return fsops.OpenZipFS(archivePath, password)
+}
+
+// entryName переводит путь внутри архива в имя для fs.FS: без
+// начального "/", корень — ".".
+func entryName(name string) string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AlanMute/file-manager/internal/zipmenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+func zipComment(args []string) error {
+	fs := flag.NewFlagSet("zip comment", flag.ContinueOnError)
+	text := fs.String("set", "", "новый комментарий; пустая строка удаляет комментарий")
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == "set" })
+
+	archivePath, err := zipPath(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case set && fs.NArg() == 2:
+		return fsops.SetZipEntryComment(archivePath, fs.Arg(1), *text)
+	case set:
+		return fsops.SetZipComment(archivePath, *text)
+	case fs.NArg() == 2:
+		meta, err := fsops.ZipEntryMetadata(archivePath, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		fmt.Println(meta.Comment)
+		return nil
+	default:
+		comment, err := fsops.ZipComment(archivePath)
+		if err != nil {
+			return err
+		}
+		fmt.Println(comment)
+		return nil
+	}
+}
+
+func zipTouch(args []string) error {
+	fs := flag.NewFlagSet("zip touch", flag.ContinueOnError)
+	when := fs.String("time", "now", "новое время изменения: ГГГГ-ММ-ДД[ ЧЧ:ММ[:СС]], RFC 3339 или now")
+	if err := parseFlags(fs, args, 2, -1); err != nil {
+		return err
+	}
+	modified, err := zipmenu.ParseTime(*when)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return errUsage
+	}
+
+	archivePath, err := zipPath(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	changed, err := fsops.SetZipModified(archivePath, fs.Args()[1:], modified)
+	if err != nil {
+		return err
+	}
+	for _, name := range changed {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func zipInfo(args []string) error {
+	fs := flag.NewFlagSet("zip info", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	archivePath, err := zipPath(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	meta, err := fsops.ZipEntryMetadata(archivePath, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	zipmenu.PrintEntryMetadata(os.Stdout, meta)
+	return nil
+}
+
+// zipPath находит архив как openArchive, но принимает только zip.
+func zipPath(name string) (string, error) {
+	archive, err := openArchive(name)
+	if err != nil {
+		return "", err
+	}
+	if archive.Format() != fsops.FormatZip {
+		return "", fmt.Errorf("%s: %w: команда работает только с zip", archive.Path(), fsops.ErrUnsupported)
+	}
+	return archive.Path(), nil
+}
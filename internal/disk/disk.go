@@ -2,6 +2,8 @@ package disk
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/AlanMute/file-manager/pkg/util"
 	"github.com/inancgumus/screen"
@@ -14,25 +16,32 @@ func ShowDiskInfo() {
 
 	fmt.Println("--- Информация о дисках ---")
 
-	partitions, err := disk.Partitions(true)
-	if err != nil {
+	if err := PrintInfo(os.Stdout); err != nil {
 		fmt.Printf("Ошибка при получении информации о дисках: %v\n", err)
 		return
 	}
+	util.Pause()
+}
+
+func PrintInfo(w io.Writer) error {
+	partitions, err := disk.Partitions(true)
+	if err != nil {
+		return err
+	}
 
 	for _, partition := range partitions {
-		fmt.Printf("Имя диска: %s\n", partition.Device)
-		fmt.Printf("Тип файловой системы: %s\n", partition.Fstype)
+		fmt.Fprintf(w, "Имя диска: %s\n", partition.Device)
+		fmt.Fprintf(w, "Тип файловой системы: %s\n", partition.Fstype)
 
 		usage, err := disk.Usage(partition.Mountpoint)
 		if err != nil {
-			fmt.Printf("Ошибка при получении информации о диске %s: %v\n", partition.Device, err)
+			fmt.Fprintf(w, "Ошибка при получении информации о диске %s: %v\n", partition.Device, err)
 			continue
 		}
-		fmt.Printf("Общий размер: %v байт\n", usage.Total)
-		fmt.Printf("Свободно: %v байт\n", usage.Free)
-		fmt.Printf("Использовано: %.2f%%\n", usage.UsedPercent)
-		fmt.Println("----------------------")
+		fmt.Fprintf(w, "Общий размер: %v байт\n", usage.Total)
+		fmt.Fprintf(w, "Свободно: %v байт\n", usage.Free)
+		fmt.Fprintf(w, "Использовано: %.2f%%\n", usage.UsedPercent)
+		fmt.Fprintln(w, "----------------------")
 	}
-	util.Pause()
+	return nil
 }
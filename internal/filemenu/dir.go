@@ -0,0 +1,226 @@
+package filemenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/AlanMute/file-manager/pkg/workspace"
+	"github.com/inancgumus/screen"
+)
+
+func PrintDir(w io.Writer, path string) error {
+	infos, err := fsops.ListDir(path)
+	if err != nil {
+		return err
+	}
+	return PrintInfos(w, infos)
+}
+
+// PrintInfos выводит таблицу файлов так же, как PrintDir; подходит для
+// любых fs.FileInfo, в том числе записей архива.
+func PrintInfos(w io.Writer, infos []fs.FileInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Тип\tПрава\tРазмер\tИзменён\tИмя")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n",
+			typeName(info.Mode()), info.Mode().Perm(), info.Size(),
+			info.ModTime().Format("2006-01-02 15:04"), info.Name())
+	}
+	return tw.Flush()
+}
+
+func PrintTree(w io.Writer, path string, maxDepth int) error {
+	tree, err := fsops.Tree(path, maxDepth)
+	if err != nil {
+		return err
+	}
+
+	PrintTreeNode(w, path, tree)
+	return nil
+}
+
+// PrintTreeNode выводит дерево, построенное fsops.Tree или fsops.TreeFS,
+// с заголовком title.
+func PrintTreeNode(w io.Writer, title string, tree *fsops.TreeNode) {
+	fmt.Fprintln(w, title)
+	printTreeChildren(w, tree, "")
+}
+
+func printTreeChildren(w io.Writer, node *fsops.TreeNode, prefix string) {
+	if node.Err != nil {
+		fmt.Fprintf(w, "%s└── [ошибка: %v]\n", prefix, node.Err)
+		return
+	}
+
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		name := child.Info.Name()
+		if child.Info.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintln(w, prefix+branch+name)
+		printTreeChildren(w, child, prefix+indent)
+	}
+}
+
+func typeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "папка"
+	case mode&fs.ModeSymlink != 0:
+		return "ссылка"
+	case mode.IsRegular():
+		return "файл"
+	default:
+		return "другое"
+	}
+}
+
+func listDir() {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	dirPath, err := workspace.Dir()
+	if err != nil {
+		fmt.Println("Ошибка при получении текущей папки:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Println("--- Содержимое папки", dirPath, "---")
+	if err := PrintDir(os.Stdout, dirPath); err != nil {
+		fmt.Println("Ошибка при чтении папки:", err)
+	}
+	util.Pause()
+}
+
+func changeDir(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Переход в папку ---")
+	fmt.Print("Введите имя папки (.. — на уровень выше, пусто — в рабочую папку): ")
+	scanner.Scan()
+	name := scanner.Text()
+
+	if name == "" {
+		rootPath, err := workspace.Root()
+		if err != nil {
+			fmt.Println("Ошибка при переходе в папку:", err)
+			util.Pause()
+			return
+		}
+		name = rootPath
+	}
+
+	if err := workspace.Chdir(name); err != nil {
+		fmt.Println("Ошибка при переходе в папку:", err)
+		util.Pause()
+		return
+	}
+
+	dirPath, _ := workspace.Dir()
+	fmt.Println("Текущая папка:", dirPath)
+	util.Pause()
+}
+
+func makeDir(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Создание папки ---")
+	fmt.Print("Введите имя папки для создания: ")
+	scanner.Scan()
+	name := scanner.Text()
+
+	fullPath, err := util.ResolvePath(scanner, name)
+	if err == nil {
+		err = fsops.Mkdir(fullPath)
+	}
+	if err != nil {
+		fmt.Println("Ошибка при создании папки:", err)
+	} else {
+		fmt.Println("Папка создана по пути:", fullPath)
+	}
+	util.Pause()
+}
+
+func removeDir(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Удаление папки ---")
+	fmt.Print("Введите имя папки для удаления: ")
+	scanner.Scan()
+	name := scanner.Text()
+
+	fullPath, err := util.ResolvePath(scanner, name)
+	if err != nil {
+		fmt.Println("Ошибка при удалении папки:", err)
+		util.Pause()
+		return
+	}
+
+	err = fsops.Rmdir(fullPath, false)
+	if errors.Is(err, fsops.ErrNotEmpty) {
+		if !util.Confirm(scanner, "Папка не пуста. Переместить её вместе с содержимым в корзину?") {
+			fmt.Println("Удаление отменено.")
+			util.Pause()
+			return
+		}
+		if err = trash.Put(fullPath); err == nil {
+			fmt.Println("Папка перемещена в корзину:", fullPath)
+			util.Pause()
+			return
+		}
+	}
+	if err != nil {
+		fmt.Println("Ошибка при удалении папки:", err)
+	} else {
+		fmt.Println("Папка удалена по пути:", fullPath)
+	}
+	util.Pause()
+}
+
+func showTree(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Дерево папок ---")
+	fmt.Print("Введите глубину дерева (пусто — без ограничения): ")
+	scanner.Scan()
+
+	maxDepth := 0
+	if text := strings.TrimSpace(scanner.Text()); text != "" {
+		depth, err := strconv.Atoi(text)
+		if err != nil || depth < 0 {
+			fmt.Println("Неверная глубина:", text)
+			util.Pause()
+			return
+		}
+		maxDepth = depth
+	}
+
+	dirPath, err := workspace.Dir()
+	if err == nil {
+		err = PrintTree(os.Stdout, dirPath, maxDepth)
+	}
+	if err != nil {
+		fmt.Println("Ошибка при построении дерева:", err)
+	}
+	util.Pause()
+}
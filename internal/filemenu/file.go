@@ -2,11 +2,13 @@ package filemenu
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
 	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/AlanMute/file-manager/pkg/workspace"
 	"github.com/inancgumus/screen"
 )
 
@@ -15,12 +17,23 @@ func ShowMenu(scanner *bufio.Scanner) {
 		screen.Clear()
 		screen.MoveTopLeft()
 
+		dirPath, _ := workspace.Dir()
+
 		fmt.Println("--- Работа с файлами ---")
+		fmt.Println("Текущая папка:", workspace.Rel(dirPath))
 		fmt.Println("1. Создать файл")
 		fmt.Println("2. Записать в файл строку")
 		fmt.Println("3. Прочитать файл")
 		fmt.Println("4. Удалить файл")
-		fmt.Println("5. Назад в главное меню")
+		fmt.Println("5. Копировать")
+		fmt.Println("6. Переместить")
+		fmt.Println("7. Переименовать")
+		fmt.Println("8. Показать содержимое папки")
+		fmt.Println("9. Перейти в папку")
+		fmt.Println("10. Создать папку")
+		fmt.Println("11. Удалить папку")
+		fmt.Println("12. Показать дерево папок")
+		fmt.Println("13. Назад в главное меню")
 
 		fmt.Print("Выберите действие: ")
 		scanner.Scan()
@@ -36,6 +49,22 @@ func ShowMenu(scanner *bufio.Scanner) {
 		case "4":
 			deleteFile(scanner)
 		case "5":
+			copyItem(scanner)
+		case "6":
+			moveItem(scanner)
+		case "7":
+			renameItem(scanner)
+		case "8":
+			listDir()
+		case "9":
+			changeDir(scanner)
+		case "10":
+			makeDir(scanner)
+		case "11":
+			removeDir(scanner)
+		case "12":
+			showTree(scanner)
+		case "13":
 			return
 		default:
 			fmt.Println("Неверный выбор, попробуйте снова.")
@@ -52,22 +81,15 @@ func createFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
-		return
+	fullPath, err := util.ResolvePath(scanner, filename)
+	if err == nil {
+		err = fsops.CreateFile(fullPath)
 	}
-
-	fullPath := filepath.Join(documentsPath, filename)
-
-	file, err := os.Create(fullPath)
 	if err != nil {
 		fmt.Println("Ошибка при создании файла:", err)
 		util.Pause()
 		return
 	}
-	defer file.Close()
 
 	fmt.Println("Файл создан по пути:", fullPath)
 	util.Pause()
@@ -82,28 +104,15 @@ func writeToFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
-		return
-	}
-
-	fullPath := filepath.Join(documentsPath, filename)
-
-	file, err := os.OpenFile(fullPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		fmt.Println("Ошибка при открытии файла:", err)
-		util.Pause()
-		return
-	}
-	defer file.Close()
-
 	fmt.Print("Введите строку для записи: ")
 	scanner.Scan()
 	text := scanner.Text()
 
-	if _, err := file.WriteString(text + "\n"); err != nil {
+	fullPath, err := util.ResolvePath(scanner, filename)
+	if err == nil {
+		err = fsops.AppendFile(fullPath, text+"\n")
+	}
+	if err != nil {
 		fmt.Println("Ошибка при записи в файл:", err)
 	} else {
 		fmt.Println("Строка записана в файл по пути:", fullPath)
@@ -120,21 +129,24 @@ func readFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fullPath, err := util.ResolvePath(scanner, filename)
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println(err)
 		util.Pause()
 		return
 	}
 
-	fullPath := filepath.Join(documentsPath, filename)
-
-	data, err := os.ReadFile(fullPath)
-	if err != nil {
+	data, err := fsops.ReadFile(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
 		fmt.Println("Данного файла не существует")
 		util.Pause()
 		return
 	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении файла:", err)
+		util.Pause()
+		return
+	}
 
 	fmt.Println("Содержимое файла по пути:", fullPath)
 	fmt.Println(string(data))
@@ -150,20 +162,25 @@ func deleteFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fullPath, err := util.ResolvePath(scanner, filename)
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при удалении файла:", err)
+		util.Pause()
+		return
+	}
+	if !util.Confirm(scanner, "Переместить "+fullPath+" в корзину?") {
+		fmt.Println("Удаление отменено.")
 		util.Pause()
 		return
 	}
 
-	fullPath := filepath.Join(documentsPath, filename)
-
-	err = os.Remove(fullPath)
-	if err != nil {
+	err = trash.Put(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
 		fmt.Println("Данного файла не существует")
+	} else if err != nil {
+		fmt.Println("Ошибка при удалении файла:", err)
 	} else {
-		fmt.Println("Файл успешно удалён по пути:", fullPath)
+		fmt.Println("Файл перемещён в корзину:", fullPath)
 	}
 	util.Pause()
 }
@@ -0,0 +1,89 @@
+package filemenu
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func copyItem(scanner *bufio.Scanner) {
+	transferItem(scanner, "Копирование", "скопировано", fsops.Copy)
+}
+
+func moveItem(scanner *bufio.Scanner) {
+	transferItem(scanner, "Перемещение", "перемещено", fsops.Move)
+}
+
+func transferItem(scanner *bufio.Scanner, title, done string, transfer func(src, dst string, opts fsops.TransferOptions) error) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("---", title, "---")
+	fmt.Print("Введите имя файла или папки: ")
+	scanner.Scan()
+	srcPath, err := util.ResolvePath(scanner, scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Print("Введите путь назначения (файл или существующая папка): ")
+	scanner.Scan()
+	dstPath, err := util.ResolvePath(scanner, scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		util.Pause()
+		return
+	}
+	dstPath = fsops.IntoDir(srcPath, dstPath)
+
+	ctx, stop := util.WithInterrupt()
+	defer stop()
+	opts := fsops.TransferOptions{
+		OnConflict: util.AskConflict(scanner),
+		Ctx:        ctx,
+		Progress:   util.NewProgressBar(os.Stdout).Update,
+	}
+
+	if err := transfer(srcPath, dstPath, opts); errors.Is(err, context.Canceled) {
+		fmt.Println("Операция прервана, недописанные файлы удалены.")
+	} else if err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		fmt.Printf("Успешно %s: %s -> %s\n", done, srcPath, dstPath)
+	}
+	util.Pause()
+}
+
+func renameItem(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Переименование ---")
+	fmt.Print("Введите имя файла или папки: ")
+	scanner.Scan()
+	path, err := util.ResolvePath(scanner, scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка при переименовании:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Print("Введите новое имя: ")
+	scanner.Scan()
+	newName := scanner.Text()
+
+	if err := fsops.Rename(path, newName, fsops.TransferOptions{OnConflict: util.AskConflict(scanner)}); err != nil {
+		fmt.Println("Ошибка при переименовании:", err)
+	} else {
+		fmt.Println("Переименовано:", path, "->", newName)
+	}
+	util.Pause()
+}
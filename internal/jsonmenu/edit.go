@@ -0,0 +1,221 @@
+package jsonmenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// editor хранит загруженный документ до сохранения в файл.
+type editor struct {
+	path     string
+	doc      *jsondoc.Value
+	style    jsondoc.Style
+	modified bool
+}
+
+func editJsonFile(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Редактирование JSON файла ---")
+	fmt.Print("Введите имя JSON файла для редактирования (без .json): ")
+	scanner.Scan()
+	filename := scanner.Text()
+
+	fullPath, err := util.ResolvePath(scanner, filename+".json")
+	if err != nil {
+		fmt.Println(err)
+		util.Pause()
+		return
+	}
+
+	doc, style, err := jsondoc.ReadFile(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
+		fmt.Println("Данного файла не существует")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении JSON файла:", err)
+		util.Pause()
+		return
+	}
+
+	e := &editor{path: fullPath, doc: doc, style: style}
+	for {
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		status := ""
+		if e.modified {
+			status = " (изменён)"
+		}
+		fmt.Printf("--- %s%s ---\n", fullPath, status)
+		fmt.Println("Пути записываются как server.ports[0]; [-1] — последний элемент массива.")
+		fmt.Println("1. Показать значение")
+		fmt.Println("2. Задать значение")
+		fmt.Println("3. Удалить значение")
+		fmt.Println("4. Переименовать ключ")
+		fmt.Println("5. Добавить элемент в массив")
+		fmt.Println("6. Сохранить")
+		fmt.Println("7. Выйти из редактора")
+		fmt.Print("Выберите действие: ")
+		scanner.Scan()
+
+		switch strings.TrimSpace(scanner.Text()) {
+		case "1":
+			e.show(scanner)
+		case "2":
+			e.set(scanner)
+		case "3":
+			e.delete(scanner)
+		case "4":
+			e.rename(scanner)
+		case "5":
+			e.append(scanner)
+		case "6":
+			e.save()
+			util.Pause()
+		case "7":
+			if e.modified && util.Confirm(scanner, "Сохранить изменения в "+fullPath+"?") {
+				e.save()
+				util.Pause()
+			}
+			return
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
+
+func (e *editor) askPath(scanner *bufio.Scanner, prompt string) (jsondoc.Path, bool) {
+	fmt.Print(prompt)
+	scanner.Scan()
+	path, err := jsondoc.ParsePath(scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		util.Pause()
+		return nil, false
+	}
+	return path, true
+}
+
+func (e *editor) indent() string {
+	if e.style.Indent == "" {
+		return jsondoc.DefaultStyle.Indent
+	}
+	return e.style.Indent
+}
+
+func (e *editor) show(scanner *bufio.Scanner) {
+	path, ok := e.askPath(scanner, "Введите путь (пусто — весь документ): ")
+	if !ok {
+		return
+	}
+
+	value, err := jsondoc.Get(e.doc, path)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		fmt.Printf("Тип: %s\n", value.Kind)
+		fmt.Println(value.Text(e.indent()))
+	}
+	util.Pause()
+}
+
+func (e *editor) set(scanner *bufio.Scanner) {
+	path, ok := e.askPath(scanner, "Введите путь (отсутствующие ключи будут созданы, [] — новый элемент массива): ")
+	if !ok {
+		return
+	}
+	if old, err := jsondoc.Get(e.doc, path); err == nil {
+		fmt.Println("Текущее значение:", old.Text(""))
+	}
+
+	fmt.Print("Введите новое значение: ")
+	scanner.Scan()
+	value, err := askValue(scanner, scanner.Text())
+	if err == nil {
+		var updated *jsondoc.Value
+		if updated, err = jsondoc.Set(e.doc, path, value); err == nil {
+			e.doc = updated
+			e.modified = true
+			fmt.Printf("%s = %s (%s)\n", path, value.Text(""), value.Kind)
+		}
+	}
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	}
+	util.Pause()
+}
+
+func (e *editor) delete(scanner *bufio.Scanner) {
+	path, ok := e.askPath(scanner, "Введите путь удаляемого значения: ")
+	if !ok {
+		return
+	}
+
+	removed, err := jsondoc.Delete(e.doc, path)
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		e.modified = true
+		fmt.Println("Удалено:", path, "=", removed.Text(""))
+	}
+	util.Pause()
+}
+
+func (e *editor) rename(scanner *bufio.Scanner) {
+	path, ok := e.askPath(scanner, "Введите путь переименовываемого ключа: ")
+	if !ok {
+		return
+	}
+	fmt.Print("Введите новое имя ключа: ")
+	scanner.Scan()
+	key := scanner.Text()
+
+	if err := jsondoc.Rename(e.doc, path, key); err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		e.modified = true
+		fmt.Println("Ключ", path, "переименован в", key)
+	}
+	util.Pause()
+}
+
+func (e *editor) append(scanner *bufio.Scanner) {
+	path, ok := e.askPath(scanner, "Введите путь массива (пусто — корень документа): ")
+	if !ok {
+		return
+	}
+
+	fmt.Print("Введите значение нового элемента: ")
+	scanner.Scan()
+	value, err := askValue(scanner, scanner.Text())
+	if err == nil {
+		err = jsondoc.Append(e.doc, path, value)
+	}
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		e.modified = true
+		fmt.Println("Элемент добавлен в", path.String()+"[]")
+	}
+	util.Pause()
+}
+
+func (e *editor) save() {
+	if err := jsondoc.WriteFile(e.path, e.doc, e.style); err != nil {
+		fmt.Println("Ошибка при записи JSON в файл:", err)
+		return
+	}
+	e.modified = false
+	fmt.Println("Изменения сохранены в", e.path)
+}
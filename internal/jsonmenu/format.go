@@ -0,0 +1,139 @@
+package jsonmenu
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// contextRunes — сколько символов строки показывается вокруг места
+// ошибки; в файлах, записанных в одну строку, строка может быть огромной.
+const contextRunes = 60
+
+func formatJsonFile(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Форматирование JSON файла ---")
+	fmt.Print("Введите имя JSON файла (без .json): ")
+	scanner.Scan()
+	filename := scanner.Text()
+
+	fullPath, data, ok := readJsonData(scanner, filename)
+	if !ok {
+		return
+	}
+	doc, err := jsondoc.Parse(data)
+	if err != nil {
+		fmt.Println("Ошибка синтаксиса JSON:", err)
+		fmt.Print(ErrorContext(data, err))
+		util.Pause()
+		return
+	}
+	fmt.Println("Синтаксис JSON корректен.")
+
+	fmt.Println("1. Отформатировать с отступами")
+	fmt.Println("2. Записать в одну строку")
+	fmt.Println("3. Оставить как есть")
+	fmt.Print("Выберите действие: ")
+	scanner.Scan()
+
+	style := jsondoc.DefaultStyle
+	switch strings.TrimSpace(scanner.Text()) {
+	case "1":
+		fmt.Print("Отступ (число пробелов или tab, пусто — 2 пробела): ")
+		scanner.Scan()
+		if text := strings.TrimSpace(scanner.Text()); text != "" {
+			if style.Indent, err = jsondoc.ParseIndent(text); err != nil {
+				fmt.Println("Ошибка:", err)
+				util.Pause()
+				return
+			}
+		}
+	case "2":
+		style.Indent = ""
+	default:
+		return
+	}
+
+	if util.Confirm(scanner, "Отсортировать ключи объектов по алфавиту?") {
+		jsondoc.SortKeys(doc)
+	}
+
+	if err := jsondoc.WriteFile(fullPath, doc, style); err != nil {
+		fmt.Println("Ошибка при записи JSON в файл:", err)
+		util.Pause()
+		return
+	}
+	if info, err := os.Stat(fullPath); err == nil {
+		fmt.Printf("Файл %s отформатирован: %d -> %d байт.\n", fullPath, len(data), info.Size())
+	}
+	util.Pause()
+}
+
+// readJsonData читает файл name.json из рабочей папки и сообщает об
+// ошибке сам.
+func readJsonData(scanner *bufio.Scanner, name string) (string, []byte, bool) {
+	fullPath, err := util.ResolvePath(scanner, name+".json")
+	if err != nil {
+		fmt.Println(err)
+		util.Pause()
+		return "", nil, false
+	}
+
+	data, err := fsops.ReadFile(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
+		fmt.Println("Данного файла не существует")
+		util.Pause()
+		return "", nil, false
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении JSON файла:", err)
+		util.Pause()
+		return "", nil, false
+	}
+	return fullPath, data, true
+}
+
+// ErrorContext возвращает строку документа с местом ошибки разбора и
+// отметку столбца под ней или пустую строку, если err не ошибка разбора.
+func ErrorContext(data []byte, err error) string {
+	var syntaxErr *jsondoc.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return ""
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if syntaxErr.Line > len(lines) {
+		return ""
+	}
+
+	line := []rune(strings.TrimRight(string(lines[syntaxErr.Line-1]), "\r"))
+	col := min(syntaxErr.Column-1, len(line))
+	start := max(0, col-contextRunes)
+	end := min(len(line), col+contextRunes)
+
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	// Табуляции в отметке сохраняются, чтобы ^ встал под нужный символ.
+	var marker strings.Builder
+	for _, r := range prefix + string(line[start:col]) {
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+
+	number := fmt.Sprint(syntaxErr.Line)
+	return fmt.Sprintf("%s | %s%s\n%s | %s^\n", number, prefix, string(line[start:end]), strings.Repeat(" ", len(number)), marker.String())
+}
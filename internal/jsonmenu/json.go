@@ -2,11 +2,14 @@ package jsonmenu
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/trash"
 	"github.com/AlanMute/file-manager/pkg/util"
 	"github.com/inancgumus/screen"
 )
@@ -20,8 +23,12 @@ func ShowMenu(scanner *bufio.Scanner) {
 		fmt.Println("1. Создать JSON файл")
 		fmt.Println("2. Создать объект и сериализовать в JSON")
 		fmt.Println("3. Прочитать JSON файл")
-		fmt.Println("4. Удалить JSON файл")
-		fmt.Println("5. Назад в главное меню")
+		fmt.Println("4. Редактировать JSON файл")
+		fmt.Println("5. Поиск в JSON файле (JSONPath)")
+		fmt.Println("6. Проверить JSON файл по схеме")
+		fmt.Println("7. Форматировать JSON файл")
+		fmt.Println("8. Удалить JSON файл")
+		fmt.Println("9. Назад в главное меню")
 
 		fmt.Print("Выберите действие: ")
 		scanner.Scan()
@@ -35,8 +42,16 @@ func ShowMenu(scanner *bufio.Scanner) {
 		case "3":
 			readJsonFile(scanner)
 		case "4":
-			deleteJsonFile(scanner)
+			editJsonFile(scanner)
 		case "5":
+			queryJsonFile(scanner)
+		case "6":
+			validateJsonFile(scanner)
+		case "7":
+			formatJsonFile(scanner)
+		case "8":
+			deleteJsonFile(scanner)
+		case "9":
 			return
 		default:
 			fmt.Println("Неверный выбор, попробуйте снова.")
@@ -44,6 +59,12 @@ func ShowMenu(scanner *bufio.Scanner) {
 	}
 }
 
+type Person struct {
+	Name  string `json:"name"`
+	Age   int    `json:"age"`
+	Email string `json:"email"`
+}
+
 func createJsonFile(scanner *bufio.Scanner) {
 	screen.Clear()
 	screen.MoveTopLeft()
@@ -53,52 +74,68 @@ func createJsonFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fmt.Println("Ключи вложенных объектов разделяются точкой, индексы массивов пишутся в скобках: server.ports[0], [] — новый элемент.")
+	doc := buildDocument(scanner)
+
+	fullPath, err := util.ResolvePath(scanner, filename+".json")
+	if err == nil {
+		err = jsondoc.WriteFile(fullPath, doc, jsondoc.DefaultStyle)
+	}
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при записи JSON в файл:", err)
 		util.Pause()
 		return
 	}
-	fullPath := filepath.Join(documentsPath, filename+".json")
 
-	data := make(map[string]string)
+	fmt.Println("JSON файл создан по пути:", fullPath)
+	util.Pause()
+}
+
+// buildDocument запрашивает пути и значения, пока не будет введён пустой
+// путь. Тип значения определяется по тексту или выбирается явно.
+func buildDocument(scanner *bufio.Scanner) *jsondoc.Value {
+	var doc *jsondoc.Value
 	for {
-		fmt.Print("Введите ключ (или оставьте пустым для завершения): ")
+		fmt.Print("Введите путь ключа (или оставьте пустым для завершения): ")
 		scanner.Scan()
-		key := scanner.Text()
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" && doc == nil {
+			return jsondoc.NewObject()
+		}
 		if key == "" {
-			break
+			return doc
+		}
+		path, err := jsondoc.ParsePath(key)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			continue
 		}
 
 		fmt.Print("Введите значение для ключа ", key, ": ")
 		scanner.Scan()
-		value := scanner.Text()
-
-		data[key] = value
-	}
-
-	fileData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		fmt.Println("Ошибка при сериализации данных в JSON:", err)
-		util.Pause()
-		return
-	}
+		text := scanner.Text()
 
-	err = os.WriteFile(fullPath, fileData, 0644)
-	if err != nil {
-		fmt.Println("Ошибка при записи JSON в файл:", err)
-		util.Pause()
-		return
+		value, err := askValue(scanner, text)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			continue
+		}
+		// Set возвращает nil при ошибке, документ при этом не меняется.
+		updated, err := jsondoc.Set(doc, path, value)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			continue
+		}
+		doc = updated
+		fmt.Printf("  %s = %s (%s)\n", path, value.Text(""), value.Kind)
 	}
-
-	fmt.Println("JSON файл создан по пути:", fullPath)
-	util.Pause()
 }
 
-type Person struct {
-	Name  string `json:"name"`
-	Age   int    `json:"age"`
-	Email string `json:"email"`
+// askValue спрашивает тип значения и разбирает text.
+func askValue(scanner *bufio.Scanner, text string) (*jsondoc.Value, error) {
+	fmt.Printf("Тип значения (%s; пусто — определить автоматически): ", strings.Join(jsondoc.Types[1:], ", "))
+	scanner.Scan()
+	return jsondoc.ParseScalar(text, scanner.Text())
 }
 
 func serializeToJson(scanner *bufio.Scanner) {
@@ -110,14 +147,6 @@ func serializeToJson(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
-		return
-	}
-	fullPath := filepath.Join(documentsPath, filename+".json")
-
 	var person Person
 	fmt.Print("Введите имя: ")
 	scanner.Scan()
@@ -131,14 +160,10 @@ func serializeToJson(scanner *bufio.Scanner) {
 	scanner.Scan()
 	person.Email = scanner.Text()
 
-	fileData, err := json.MarshalIndent(person, "", "  ")
-	if err != nil {
-		fmt.Println("Ошибка при сериализации объекта в JSON:", err)
-		util.Pause()
-		return
+	fullPath, err := util.ResolvePath(scanner, filename+".json")
+	if err == nil {
+		err = fsops.WriteJSON(fullPath, person)
 	}
-
-	err = os.WriteFile(fullPath, fileData, 0644)
 	if err != nil {
 		fmt.Println("Ошибка при записи JSON в файл:", err)
 		util.Pause()
@@ -158,23 +183,29 @@ func readJsonFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
+	fullPath, data, ok := readJsonData(scanner, filename)
+	if !ok {
 		return
 	}
-	fullPath := filepath.Join(documentsPath, filename+".json")
-
-	data, err := os.ReadFile(fullPath)
+	doc, err := jsondoc.Parse(data)
 	if err != nil {
-		fmt.Println("Данного файла не существует")
+		fmt.Println("Ошибка синтаксиса JSON в файле", fullPath+":", err)
+		fmt.Print(ErrorContext(data, err))
 		util.Pause()
 		return
 	}
 
+	indent := jsondoc.DetectStyle(data).Indent
+	if indent == "" {
+		indent = jsondoc.DefaultStyle.Indent
+	}
 	fmt.Println("Содержимое JSON файла по пути:", fullPath)
-	fmt.Println(string(data))
+	if util.ColorEnabled(os.Stdout) {
+		doc.EncodeColor(os.Stdout, indent)
+	} else {
+		doc.Encode(os.Stdout, indent)
+	}
+	fmt.Println()
 	util.Pause()
 }
 
@@ -187,19 +218,25 @@ func deleteJsonFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fullPath, err := util.ResolvePath(scanner, filename+".json")
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при удалении JSON файла:", err)
+		util.Pause()
+		return
+	}
+	if !util.Confirm(scanner, "Переместить "+fullPath+" в корзину?") {
+		fmt.Println("Удаление отменено.")
 		util.Pause()
 		return
 	}
-	fullPath := filepath.Join(documentsPath, filename+".json")
 
-	err = os.Remove(fullPath)
-	if err != nil {
+	err = trash.Put(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
 		fmt.Println("Данного файла не существует")
+	} else if err != nil {
+		fmt.Println("Ошибка при удалении JSON файла:", err)
 	} else {
-		fmt.Println("JSON файл успешно удалён по пути:", fullPath)
+		fmt.Println("JSON файл перемещён в корзину:", fullPath)
 	}
 	util.Pause()
 }
@@ -0,0 +1,111 @@
+package jsonmenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// Ограничения вывода результатов запроса на экран.
+const (
+	maxMatches    = 100
+	maxValueRunes = 200
+)
+
+func queryJsonFile(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Поиск в JSON файле ---")
+	fmt.Print("Введите имя JSON файла (без .json): ")
+	scanner.Scan()
+	filename := scanner.Text()
+
+	fullPath, err := util.ResolvePath(scanner, filename+".json")
+	if err != nil {
+		fmt.Println(err)
+		util.Pause()
+		return
+	}
+
+	doc, _, err := jsondoc.ReadFile(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
+		fmt.Println("Данного файла не существует")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении JSON файла:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Println("Выражения JSONPath: $.store.book[0].title, $..price, $.items[*], $.items[1:3], $.items[?@.price < 10].")
+	for {
+		fmt.Print("Введите выражение (или оставьте пустым для завершения): ")
+		scanner.Scan()
+		expr := strings.TrimSpace(scanner.Text())
+		if expr == "" {
+			return
+		}
+
+		query, err := jsondoc.CompileQuery(expr)
+		if err != nil {
+			fmt.Println("Ошибка:", err)
+			continue
+		}
+		PrintMatches(scanner, query.Select(doc))
+	}
+}
+
+// PrintMatches выводит совпадения по maxMatches за раз, обрезая длинные
+// значения.
+func PrintMatches(scanner *bufio.Scanner, matches []jsondoc.Match) {
+	if len(matches) == 0 {
+		fmt.Println("Совпадений не найдено.")
+		return
+	}
+
+	for start := 0; start < len(matches); start += maxMatches {
+		end := min(start+maxMatches, len(matches))
+		for _, m := range matches[start:end] {
+			fmt.Println(m.Path.JSONPath(), "=", shorten(m.Value.Text("")))
+		}
+		if end < len(matches) && !util.Confirm(scanner, fmt.Sprintf("Показано %d из %d. Показать ещё?", end, len(matches))) {
+			break
+		}
+	}
+	fmt.Println("Совпадений:", len(matches))
+}
+
+func shorten(s string) string {
+	if utf8.RuneCountInString(s) <= maxValueRunes {
+		return s
+	}
+	return string([]rune(s)[:maxValueRunes]) + "…"
+}
+
+// WriteMatches выводит совпадения целиком: путь и значение, только пути
+// или только значения; строки в режиме значений выводятся без кавычек.
+func WriteMatches(w io.Writer, matches []jsondoc.Match, paths, values bool) {
+	for _, m := range matches {
+		switch {
+		case paths:
+			fmt.Fprintln(w, m.Path.JSONPath())
+		case values && m.Value.Kind == jsondoc.String:
+			fmt.Fprintln(w, m.Value.Str)
+		case values:
+			fmt.Fprintln(w, m.Value.Text(""))
+		default:
+			fmt.Fprintln(w, m.Path.JSONPath(), "=", m.Value.Text(""))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package jsonmenu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/jsondoc"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func validateJsonFile(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Проверка JSON файла по схеме ---")
+	fmt.Print("Введите имя JSON файла для проверки (без .json): ")
+	scanner.Scan()
+	filename := scanner.Text()
+	fmt.Print("Введите имя файла схемы JSON Schema (без .json): ")
+	scanner.Scan()
+	schemaName := scanner.Text()
+
+	violations, err := validateFiles(scanner, filename+".json", schemaName+".json")
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+	} else {
+		PrintViolations(os.Stdout, violations)
+	}
+	util.Pause()
+}
+
+// validateFiles проверяет документ name по схеме schemaName; имена
+// файлов считаются относительно рабочей папки.
+func validateFiles(scanner *bufio.Scanner, name, schemaName string) ([]jsondoc.Violation, error) {
+	schemaPath, err := util.ResolvePath(scanner, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	schemaDoc, _, err := jsondoc.ReadFile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := jsondoc.CompileSchema(schemaDoc)
+	if err != nil {
+		return nil, &fsops.Error{Op: "чтение схемы", Path: schemaPath, Err: err}
+	}
+
+	fullPath, err := util.ResolvePath(scanner, name)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := jsondoc.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Validate(doc), nil
+}
+
+func PrintViolations(w io.Writer, violations []jsondoc.Violation) {
+	if len(violations) == 0 {
+		fmt.Fprintln(w, "Документ соответствует схеме.")
+		return
+	}
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s  (схема: #%s)\n", v.Error(), v.Keyword)
+	}
+	fmt.Fprintln(w, "Нарушений:", len(violations))
+}
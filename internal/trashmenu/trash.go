@@ -0,0 +1,175 @@
+package trashmenu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func ShowMenu(scanner *bufio.Scanner) {
+	for {
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		fmt.Println("--- Корзина ---")
+		fmt.Println("1. Показать содержимое корзины")
+		fmt.Println("2. Восстановить файл")
+		fmt.Println("3. Удалить файлы старше N дней")
+		fmt.Println("4. Очистить корзину")
+		fmt.Println("5. Назад в главное меню")
+
+		fmt.Print("Выберите действие: ")
+		scanner.Scan()
+		choice := scanner.Text()
+
+		switch choice {
+		case "1":
+			listTrash()
+		case "2":
+			restoreItem(scanner)
+		case "3":
+			purgeOld(scanner)
+		case "4":
+			emptyTrash(scanner)
+		case "5":
+			return
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
+
+func PrintItems(w io.Writer, items []trash.Item) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "№\tУдалён\tИмя в корзине\tИсходный путь")
+	for i, item := range items {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, item.DeletedAt.Format("2006-01-02 15:04"), item.Name, item.OriginalPath)
+	}
+	return tw.Flush()
+}
+
+func listTrash() {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Содержимое корзины ---")
+	items, err := trash.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении корзины:", err)
+		util.Pause()
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Корзина пуста.")
+		util.Pause()
+		return
+	}
+
+	PrintItems(os.Stdout, items)
+	util.Pause()
+}
+
+func restoreItem(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Восстановление файла ---")
+	items, err := trash.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении корзины:", err)
+		util.Pause()
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Корзина пуста.")
+		util.Pause()
+		return
+	}
+
+	PrintItems(os.Stdout, items)
+	fmt.Print("\nВведите номер или имя файла в корзине: ")
+	scanner.Scan()
+	choice := strings.TrimSpace(scanner.Text())
+
+	item, ok := findItem(items, choice)
+	if !ok {
+		fmt.Println("Файл", choice, "не найден в корзине.")
+		util.Pause()
+		return
+	}
+
+	restored, err := trash.Restore(item.Name, fsops.TransferOptions{OnConflict: util.AskConflict(scanner)})
+	switch {
+	case err != nil:
+		fmt.Println("Ошибка при восстановлении файла:", err)
+	case restored:
+		fmt.Println("Файл восстановлен по пути:", item.OriginalPath)
+	default:
+		fmt.Println("Файл пропущен и остался в корзине.")
+	}
+	util.Pause()
+}
+
+func purgeOld(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Удаление старых файлов из корзины ---")
+	fmt.Print("Удалить файлы, которые лежат в корзине дольше скольких дней: ")
+	scanner.Scan()
+
+	days, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil || days <= 0 {
+		fmt.Println("Неверное число дней:", scanner.Text())
+		util.Pause()
+		return
+	}
+
+	removed, err := trash.Empty(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		fmt.Println("Ошибка при очистке корзины:", err)
+	}
+	fmt.Println("Удалено файлов:", removed)
+	util.Pause()
+}
+
+func emptyTrash(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Очистка корзины ---")
+	if !util.Confirm(scanner, "Окончательно удалить все файлы из корзины?") {
+		fmt.Println("Очистка отменена.")
+		util.Pause()
+		return
+	}
+
+	removed, err := trash.Empty(0)
+	if err != nil {
+		fmt.Println("Ошибка при очистке корзины:", err)
+	}
+	fmt.Println("Удалено файлов:", removed)
+	util.Pause()
+}
+
+func findItem(items []trash.Item, choice string) (trash.Item, bool) {
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(items) {
+		return items[n-1], true
+	}
+	for _, item := range items {
+		if item.Name == choice {
+			return item, true
+		}
+	}
+	return trash.Item{}, false
+}
@@ -2,10 +2,11 @@ package xmlmenu
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
 	"github.com/AlanMute/file-manager/pkg/util"
 	"github.com/inancgumus/screen"
 )
@@ -49,14 +50,6 @@ func createXmlFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
-		return
-	}
-	fullPath := filepath.Join(documentsPath, filename+".xml")
-
 	data := make(map[string]string)
 	for {
 		fmt.Print("Введите тег (или оставьте пустым для завершения): ")
@@ -73,13 +66,10 @@ func createXmlFile(scanner *bufio.Scanner) {
 		data[tag] = value
 	}
 
-	xmlContent := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n"
-	for tag, value := range data {
-		xmlContent += fmt.Sprintf("  <%s>%s</%s>\n", tag, value, tag)
+	fullPath, err := util.ResolvePath(scanner, filename+".xml")
+	if err == nil {
+		err = fsops.WriteXML(fullPath, data)
 	}
-	xmlContent += "</root>"
-
-	err = os.WriteFile(fullPath, []byte(xmlContent), 0644)
 	if err != nil {
 		fmt.Println("Ошибка при записи XML в файл:", err)
 		util.Pause()
@@ -99,20 +89,24 @@ func readXmlFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fullPath, err := util.ResolvePath(scanner, filename+".xml")
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println(err)
 		util.Pause()
 		return
 	}
-	fullPath := filepath.Join(documentsPath, filename+".xml")
 
-	data, err := os.ReadFile(fullPath)
-	if err != nil {
+	data, err := fsops.ReadFile(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
 		fmt.Println("Данного файла не существует")
 		util.Pause()
 		return
 	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении XML файла:", err)
+		util.Pause()
+		return
+	}
 
 	fmt.Println("Содержимое XML файла по пути:", fullPath)
 	fmt.Println(string(data))
@@ -128,19 +122,25 @@ func deleteXmlFile(scanner *bufio.Scanner) {
 	scanner.Scan()
 	filename := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	fullPath, err := util.ResolvePath(scanner, filename+".xml")
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при удалении XML файла:", err)
+		util.Pause()
+		return
+	}
+	if !util.Confirm(scanner, "Переместить "+fullPath+" в корзину?") {
+		fmt.Println("Удаление отменено.")
 		util.Pause()
 		return
 	}
-	fullPath := filepath.Join(documentsPath, filename+".xml")
 
-	err = os.Remove(fullPath)
-	if err != nil {
+	err = trash.Put(fullPath)
+	if errors.Is(err, fsops.ErrNotFound) {
 		fmt.Println("Данного файла не существует")
+	} else if err != nil {
+		fmt.Println("Ошибка при удалении XML файла:", err)
 	} else {
-		fmt.Println("XML файл успешно удалён по пути:", fullPath)
+		fmt.Println("XML файл перемещён в корзину:", fullPath)
 	}
 	util.Pause()
 }
@@ -0,0 +1,280 @@
+package zipmenu
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlanMute/file-manager/internal/filemenu"
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// viewLimit — сколько байт файла из архива показывается на экране.
+const viewLimit = 64 << 10
+
+// browser хранит открытый архив и текущую папку внутри него.
+type browser struct {
+	archive  fsops.Archive
+	password string
+	fsys     *fsops.ZipFS
+	dir      string
+}
+
+func browseArchive(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Обзор архива ---")
+	archive, ok := openArchive(scanner)
+	if !ok {
+		return
+	}
+	if archive.Format() != fsops.FormatZip {
+		fmt.Println("Открыть как папку можно только zip архив.")
+		util.Pause()
+		return
+	}
+
+	entries, err := archive.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении архива:", err)
+		util.Pause()
+		return
+	}
+
+	b := &browser{archive: archive, password: askPassword(scanner, entries), dir: "."}
+	if err := b.reopen(); err != nil {
+		fmt.Println("Ошибка при открытии архива:", err)
+		util.Pause()
+		return
+	}
+	defer func() { b.fsys.Close() }()
+
+	for {
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		fmt.Printf("--- %s:/%s ---\n", filepath.Base(archive.Path()), strings.TrimPrefix(b.dir, "."))
+		infos, err := fsops.ListDirFS(b.fsys, b.dir)
+		if err == nil {
+			err = filemenu.PrintInfos(os.Stdout, infos)
+		}
+		if err != nil {
+			fmt.Println("Ошибка при чтении папки:", err)
+		}
+
+		fmt.Println()
+		fmt.Println("1. Перейти в папку")
+		fmt.Println("2. Показать дерево")
+		fmt.Println("3. Просмотреть файл")
+		fmt.Println("4. Скопировать из архива")
+		fmt.Println("5. Скопировать в архив")
+		fmt.Println("6. Выйти из архива")
+		fmt.Print("Выберите действие: ")
+		scanner.Scan()
+
+		switch scanner.Text() {
+		case "1":
+			b.changeDir(scanner)
+		case "2":
+			b.showTree()
+		case "3":
+			b.view(scanner)
+		case "4":
+			b.copyOut(scanner)
+		case "5":
+			b.copyIn(scanner)
+		case "6":
+			return
+		default:
+			fmt.Println("Неверный выбор, попробуйте снова.")
+		}
+	}
+}
+
+func (b *browser) reopen() error {
+	if b.fsys != nil {
+		b.fsys.Close()
+	}
+	fsys, err := fsops.OpenZipFS(b.archive.Path(), b.password)
+	if err != nil {
+		return err
+	}
+	b.fsys = fsys
+	return nil
+}
+
+// resolve переводит введённое имя в путь внутри архива: относительно
+// текущей папки или от корня, если имя начинается с "/".
+func (b *browser) resolve(name string) string {
+	name = strings.TrimSpace(name)
+	if !strings.HasPrefix(name, "/") {
+		name = b.dir + "/" + name
+	}
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (b *browser) changeDir(scanner *bufio.Scanner) {
+	fmt.Print("Введите имя папки (.. — на уровень выше, / — корень архива): ")
+	scanner.Scan()
+	dir := b.resolve(scanner.Text())
+
+	info, err := fs.Stat(b.fsys, dir)
+	if err == nil && !info.IsDir() {
+		err = fsops.ErrNotDir
+	}
+	if err != nil {
+		fmt.Println("Ошибка при переходе в папку:", err)
+		util.Pause()
+		return
+	}
+	b.dir = dir
+}
+
+func (b *browser) showTree() {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	tree, err := fsops.TreeFS(b.fsys, b.dir, 0)
+	if err != nil {
+		fmt.Println("Ошибка при построении дерева:", err)
+	} else {
+		filemenu.PrintTreeNode(os.Stdout, "/"+strings.TrimPrefix(b.dir, "."), tree)
+	}
+	util.Pause()
+}
+
+func (b *browser) view(scanner *bufio.Scanner) {
+	fmt.Print("Введите имя файла: ")
+	scanner.Scan()
+	name := b.resolve(scanner.Text())
+
+	file, err := b.fsys.Open(name)
+	if err != nil {
+		fmt.Println("Ошибка при открытии файла:", err)
+		util.Pause()
+		return
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		fmt.Println("Ошибка:", name, fsops.ErrUnsupported)
+		util.Pause()
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, viewLimit+1))
+	if errors.Is(err, fsops.ErrWrongPassword) {
+		fmt.Println("Неверный пароль.")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении файла:", err)
+		util.Pause()
+		return
+	}
+
+	screen.Clear()
+	screen.MoveTopLeft()
+	fmt.Println("Содержимое файла", name+":")
+	if len(data) > viewLimit {
+		fmt.Println(string(data[:viewLimit]))
+		fmt.Printf("... показаны первые %d КБ, чтобы увидеть весь файл, скопируйте его из архива.\n", viewLimit>>10)
+	} else {
+		fmt.Println(string(data))
+	}
+	util.Pause()
+}
+
+func (b *browser) copyOut(scanner *bufio.Scanner) {
+	fmt.Print("Введите имя файла или папки в архиве (. — текущая папка целиком): ")
+	scanner.Scan()
+	name := b.resolve(scanner.Text())
+
+	fmt.Print("Введите путь назначения (файл или существующая папка, пусто — текущая папка): ")
+	scanner.Scan()
+	dstPath, err := util.ResolvePath(scanner, scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		util.Pause()
+		return
+	}
+
+	base := path.Base(name)
+	if name == "." {
+		base = fsops.TrimArchiveExt(filepath.Base(b.archive.Path()))
+	}
+	if info, err := os.Stat(dstPath); err == nil && info.IsDir() {
+		dstPath = filepath.Join(dstPath, base)
+	}
+
+	ctx, stop := util.WithInterrupt()
+	defer stop()
+	opts := fsops.TransferOptions{
+		OnConflict: util.AskConflict(scanner),
+		Ctx:        ctx,
+		Progress:   util.NewProgressBar(os.Stdout).Update,
+		Limits:     fsops.DefaultExtractLimits,
+	}
+
+	err = fsops.CopyFromFS(b.fsys, name, dstPath, opts)
+	switch {
+	case errors.Is(err, context.Canceled):
+		fmt.Println("Копирование прервано, недописанные файлы удалены.")
+	case errors.Is(err, fsops.ErrWrongPassword):
+		fmt.Println("Неверный пароль.")
+	case err != nil:
+		fmt.Println("Ошибка при копировании:", err)
+	default:
+		fmt.Println("Скопировано:", name, "->", dstPath)
+	}
+	util.Pause()
+}
+
+func (b *browser) copyIn(scanner *bufio.Scanner) {
+	fmt.Print("Введите имя файла или папки для копирования в архив: ")
+	scanner.Scan()
+	srcPath, err := util.ResolvePath(scanner, scanner.Text())
+	if err != nil {
+		fmt.Println("Ошибка:", err)
+		util.Pause()
+		return
+	}
+
+	ctx, stop := util.WithInterrupt()
+	defer stop()
+	opts := fsops.AddOptions{
+		Dir:      strings.TrimPrefix(b.dir, "."),
+		Password: b.password,
+		Ctx:      ctx,
+		Progress: util.NewProgressBar(os.Stdout).Update,
+	}
+
+	summary, err := b.archive.Add([]string{srcPath}, opts)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Копирование прервано, архив не изменён.")
+	} else if err != nil {
+		fmt.Println("Ошибка при добавлении в архив:", err)
+	} else {
+		PrintAddSummary(os.Stdout, summary)
+	}
+
+	if err := b.reopen(); err != nil {
+		fmt.Println("Ошибка при открытии архива:", err)
+	}
+	util.Pause()
+}
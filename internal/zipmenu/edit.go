@@ -0,0 +1,86 @@
+package zipmenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func removeEntries(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Удаление записей из архива ---")
+	archivePath, ok := openZipArchive(scanner)
+	if !ok {
+		return
+	}
+
+	fmt.Print("Введите имена или шаблоны записей через пробел (папка удаляется вместе с содержимым): ")
+	scanner.Scan()
+	patterns := strings.Fields(scanner.Text())
+	if len(patterns) == 0 {
+		fmt.Println("Не указано ни одной записи.")
+		util.Pause()
+		return
+	}
+
+	removed, err := fsops.RemoveFromZip(archivePath, patterns)
+	if errors.Is(err, fsops.ErrEntryNotFound) {
+		fmt.Println("В архиве нет подходящих записей.")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при удалении записей:", err)
+		util.Pause()
+		return
+	}
+
+	for _, name := range removed {
+		fmt.Println("  -", name)
+	}
+	fmt.Println("Удалено записей:", len(removed))
+	util.Pause()
+}
+
+func renameEntry(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Переименование записи в архиве ---")
+	archivePath, ok := openZipArchive(scanner)
+	if !ok {
+		return
+	}
+
+	fmt.Print("Введите текущее имя записи или папки в архиве: ")
+	scanner.Scan()
+	oldName := scanner.Text()
+
+	fmt.Print("Введите новое имя (можно с другим путём внутри архива): ")
+	scanner.Scan()
+	newName := scanner.Text()
+
+	renamed, err := fsops.RenameInZip(archivePath, oldName, newName)
+	if errors.Is(err, fsops.ErrEntryNotFound) {
+		fmt.Println("Запись", oldName, "не найдена в архиве.")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при переименовании записи:", err)
+		util.Pause()
+		return
+	}
+
+	for _, line := range renamed {
+		fmt.Println(" ", line)
+	}
+	util.Pause()
+}
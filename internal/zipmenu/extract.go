@@ -0,0 +1,110 @@
+package zipmenu
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func extractAll(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Распаковка архива ---")
+	archive, ok := openArchive(scanner)
+	if !ok {
+		return
+	}
+
+	fmt.Print("Введите папку назначения (пусто — папка с именем архива): ")
+	scanner.Scan()
+	destName := scanner.Text()
+	if destName == "" {
+		destName = fsops.TrimArchiveExt(archive.Path())
+	}
+	destDir, err := util.ResolvePath(scanner, destName)
+	if err != nil {
+		fmt.Println(err)
+		util.Pause()
+		return
+	}
+
+	entries, err := archive.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении архива:", err)
+		util.Pause()
+		return
+	}
+
+	opts := fsops.ExtractOptions{
+		Limits:     fsops.DefaultExtractLimits,
+		OnConflict: util.AskConflict(scanner),
+		Password:   askPassword(scanner, entries),
+	}
+	fmt.Print("Распаковать только файлы по шаблонам через пробел (например *.txt docs/**, пусто — все): ")
+	scanner.Scan()
+	opts.Patterns = strings.Fields(scanner.Text())
+
+	fmt.Printf("Ограничения: не больше %d байт, %d записей, степень сжатия до %.0f.\n",
+		opts.Limits.MaxTotalSize, opts.Limits.MaxEntries, opts.Limits.MaxRatio)
+	if util.Confirm(scanner, "Изменить ограничения?") {
+		opts.Limits = askLimits(scanner, opts.Limits)
+	}
+
+	ctx, stop := util.WithInterrupt()
+	defer stop()
+	opts.Ctx = ctx
+	opts.Progress = util.NewProgressBar(os.Stdout).Update
+
+	summary, err := archive.Extract(destDir, opts)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Распаковка прервана, распакованные файлы удалены.")
+		util.Pause()
+		return
+	}
+	PrintExtractSummary(os.Stdout, summary)
+	if err != nil {
+		fmt.Println("Ошибка при распаковке архива:", err)
+	} else {
+		fmt.Println("Архив распакован в папку:", destDir)
+	}
+	util.Pause()
+}
+
+func askLimits(scanner *bufio.Scanner, limits fsops.ExtractLimits) fsops.ExtractLimits {
+	fmt.Print("Максимальный общий размер в байтах (0 — без ограничения, пусто — оставить): ")
+	scanner.Scan()
+	if n, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 64); err == nil && n >= 0 {
+		limits.MaxTotalSize = n
+	}
+
+	fmt.Print("Максимальное число записей (0 — без ограничения, пусто — оставить): ")
+	scanner.Scan()
+	if n, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil && n >= 0 {
+		limits.MaxEntries = n
+	}
+
+	fmt.Print("Максимальная степень сжатия (0 — без ограничения, пусто — оставить): ")
+	scanner.Scan()
+	if n, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64); err == nil && n >= 0 {
+		limits.MaxRatio = n
+	}
+	return limits
+}
+
+func PrintExtractSummary(w io.Writer, summary fsops.ExtractSummary) {
+	for _, name := range summary.Extracted {
+		fmt.Fprintln(w, "  ->", name)
+	}
+	fmt.Fprintf(w, "Распаковано файлов: %d (%d байт), папок: %d, ссылок: %d, пропущено: %d\n",
+		summary.Files, summary.Bytes, summary.Dirs, summary.Symlinks, summary.Skipped)
+}
@@ -0,0 +1,213 @@
+package zipmenu
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// SortColumns перечисляет столбцы, по которым можно сортировать список.
+var SortColumns = []string{"name", "size", "compressed", "ratio", "method", "crc", "modified", "mode"}
+
+var Formats = []string{"table", "json", "csv"}
+
+type listingRecord struct {
+	Name           string    `json:"name"`
+	Size           uint64    `json:"size"`
+	CompressedSize uint64    `json:"compressed_size"`
+	Ratio          float64   `json:"ratio"`
+	Method         string    `json:"method"`
+	CRC32          string    `json:"crc32"`
+	Modified       time.Time `json:"modified"`
+	Mode           string    `json:"mode"`
+	Comment        string    `json:"comment,omitempty"`
+	Encryption     string    `json:"encryption,omitempty"`
+}
+
+type listingTotals struct {
+	Entries        int     `json:"entries"`
+	Size           uint64  `json:"size"`
+	CompressedSize uint64  `json:"compressed_size"`
+	Ratio          float64 `json:"ratio"`
+}
+
+func SortListing(entries []fsops.ArchiveEntry, column string, desc bool) error {
+	var less func(a, b fsops.ArchiveEntry) bool
+	switch column {
+	case "name", "":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Name < b.Name }
+	case "size":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Size < b.Size }
+	case "compressed":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.CompressedSize < b.CompressedSize }
+	case "ratio":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Ratio() < b.Ratio() }
+	case "method":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Method < b.Method }
+	case "crc":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.CRC32 < b.CRC32 }
+	case "modified":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Modified.Before(b.Modified) }
+	case "mode":
+		less = func(a, b fsops.ArchiveEntry) bool { return a.Mode < b.Mode }
+	default:
+		return fmt.Errorf("неизвестный столбец %q, допустимы: %s", column, strings.Join(SortColumns, ", "))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if desc {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+	return nil
+}
+
+// PrintListing выводит записи таблицей, в JSON или CSV. Итоги есть в
+// таблице и в поле totals JSON; в CSV их нет намеренно: каждая строка
+// описывает одну запись, чтобы файл без правок читали таблицы и скрипты.
+func PrintListing(w io.Writer, entries []fsops.ArchiveEntry, format string) error {
+	records := make([]listingRecord, 0, len(entries))
+	var totals listingTotals
+	for _, entry := range entries {
+		records = append(records, listingRecord{
+			Name:           entry.Name,
+			Size:           entry.Size,
+			CompressedSize: entry.CompressedSize,
+			Ratio:          entry.Ratio(),
+			Method:         entry.Method,
+			CRC32:          fmt.Sprintf("%08x", entry.CRC32),
+			Modified:       entry.Modified,
+			Mode:           entry.Mode.String(),
+			Comment:        entry.Comment,
+			Encryption:     entry.Encryption,
+		})
+		totals.Entries++
+		totals.Size += entry.Size
+		totals.CompressedSize += entry.CompressedSize
+	}
+	if totals.Size > 0 {
+		totals.Ratio = 1 - float64(totals.CompressedSize)/float64(totals.Size)
+	}
+
+	switch format {
+	case "table", "":
+		return printTable(w, records, totals)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Entries []listingRecord `json:"entries"`
+			Totals  listingTotals   `json:"totals"`
+		}{records, totals})
+	case "csv":
+		return printCSV(w, records)
+	default:
+		return fmt.Errorf("неизвестный формат %q, допустимы: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func printTable(w io.Writer, records []listingRecord, totals listingTotals) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Размер\tСжато\tСжатие\tМетод\tCRC-32\tИзменён\tПрава\tИмя\tКомментарий")
+	for _, r := range records {
+		method := r.Method
+		if r.Encryption != "" {
+			method += "+" + r.Encryption
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%.1f%%\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Size, r.CompressedSize, r.Ratio*100, method, r.CRC32,
+			r.Modified.Format("2006-01-02 15:04"), r.Mode, r.Name, r.Comment)
+	}
+	fmt.Fprintf(tw, "%d\t%d\t%.1f%%\t\t\t\t\tзаписей: %d\n",
+		totals.Size, totals.CompressedSize, totals.Ratio*100, totals.Entries)
+	return tw.Flush()
+}
+
+func printCSV(w io.Writer, records []listingRecord) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"name", "size", "compressed_size", "ratio", "method", "crc32", "modified", "mode", "comment", "encryption"})
+	for _, r := range records {
+		writer.Write([]string{
+			r.Name,
+			strconv.FormatUint(r.Size, 10),
+			strconv.FormatUint(r.CompressedSize, 10),
+			strconv.FormatFloat(r.Ratio, 'f', 4, 64),
+			r.Method,
+			r.CRC32,
+			r.Modified.Format(time.RFC3339),
+			r.Mode,
+			r.Comment,
+			r.Encryption,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func listZip(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Содержимое архива ---")
+	archive, ok := openArchive(scanner)
+	if !ok {
+		return
+	}
+
+	entries, err := archive.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении архива:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Printf("Сортировать по (%s, пусто — порядок в архиве, '-' в начале — по убыванию): ", strings.Join(SortColumns, ", "))
+	scanner.Scan()
+	if column := strings.TrimSpace(scanner.Text()); column != "" {
+		desc := strings.HasPrefix(column, "-")
+		if err := SortListing(entries, strings.TrimPrefix(column, "-"), desc); err != nil {
+			fmt.Println(err)
+			util.Pause()
+			return
+		}
+	}
+
+	fmt.Print("Сохранить список в файл .json или .csv (пусто — показать на экране): ")
+	scanner.Scan()
+	exportName := strings.TrimSpace(scanner.Text())
+	if exportName == "" {
+		PrintListing(os.Stdout, entries, "table")
+		util.Pause()
+		return
+	}
+
+	format := "csv"
+	if strings.HasSuffix(strings.ToLower(exportName), ".json") {
+		format = "json"
+	}
+	exportPath, err := util.ResolvePath(scanner, exportName)
+	if err == nil {
+		err = fsops.WriteAtomic(exportPath, func(w io.Writer) error {
+			return PrintListing(w, entries, format)
+		})
+	}
+	if err != nil {
+		fmt.Println("Ошибка при сохранении списка:", err)
+	} else {
+		fmt.Println("Список сохранён по пути:", exportPath)
+	}
+	util.Pause()
+}
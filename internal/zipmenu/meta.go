@@ -0,0 +1,173 @@
+package zipmenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+// TimeLayouts — форматы, в которых можно ввести дату изменения записи.
+var TimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", time.RFC3339}
+
+func editMetadata(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Комментарии и метаданные ---")
+	archivePath, ok := openZipArchive(scanner)
+	if !ok {
+		return
+	}
+
+	comment, err := fsops.ZipComment(archivePath)
+	if err != nil {
+		fmt.Println("Ошибка при открытии архива:", err)
+		util.Pause()
+		return
+	}
+	fmt.Println("Комментарий архива:")
+	printComment(os.Stdout, comment)
+
+	fmt.Println()
+	fmt.Println("1. Изменить комментарий архива")
+	fmt.Println("2. Сведения о записи")
+	fmt.Println("3. Изменить комментарий записи")
+	fmt.Println("4. Изменить дату изменения записей")
+	fmt.Println("5. Назад")
+	fmt.Print("Выберите действие: ")
+	scanner.Scan()
+
+	switch strings.TrimSpace(scanner.Text()) {
+	case "1":
+		err = fsops.SetZipComment(archivePath, askComment(scanner))
+		if err == nil {
+			fmt.Println("Комментарий архива сохранён.")
+		}
+	case "2":
+		fmt.Print("Введите имя записи: ")
+		scanner.Scan()
+		var meta fsops.EntryMetadata
+		meta, err = fsops.ZipEntryMetadata(archivePath, scanner.Text())
+		if err == nil {
+			PrintEntryMetadata(os.Stdout, meta)
+		}
+	case "3":
+		fmt.Print("Введите имя записи: ")
+		scanner.Scan()
+		name := scanner.Text()
+		err = fsops.SetZipEntryComment(archivePath, name, askComment(scanner))
+		if err == nil {
+			fmt.Println("Комментарий записи", name, "сохранён.")
+		}
+	case "4":
+		err = touchEntries(scanner, archivePath)
+	default:
+		return
+	}
+
+	if errors.Is(err, fsops.ErrEntryNotFound) {
+		fmt.Println("Запись не найдена в архиве.")
+	} else if err != nil {
+		fmt.Println("Ошибка:", err)
+	}
+	util.Pause()
+}
+
+func touchEntries(scanner *bufio.Scanner, archivePath string) error {
+	fmt.Print("Введите имена или шаблоны записей через пробел (у папки меняется и содержимое): ")
+	scanner.Scan()
+	patterns := strings.Fields(scanner.Text())
+	if len(patterns) == 0 {
+		return errors.New("не указано ни одной записи")
+	}
+
+	fmt.Print("Новая дата в формате ГГГГ-ММ-ДД ЧЧ:ММ:СС (пусто — текущее время): ")
+	scanner.Scan()
+	modified, err := ParseTime(scanner.Text())
+	if err != nil {
+		return err
+	}
+
+	changed, err := fsops.SetZipModified(archivePath, patterns, modified)
+	if err != nil {
+		return err
+	}
+	for _, name := range changed {
+		fmt.Println("  ", name)
+	}
+	fmt.Println("Изменено записей:", len(changed))
+	return nil
+}
+
+// askComment читает комментарий из нескольких строк до пустой строки.
+func askComment(scanner *bufio.Scanner) string {
+	fmt.Println("Введите комментарий; пустая строка завершает ввод, сразу пустая — удаляет комментарий:")
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseTime разбирает дату в местном времени в одном из TimeLayouts;
+// пустая строка и "now" означают текущее время.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "now" {
+		return time.Now(), nil
+	}
+	for _, layout := range TimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверная дата %q, ожидается ГГГГ-ММ-ДД ЧЧ:ММ:СС", s)
+}
+
+func printComment(w io.Writer, comment string) {
+	if comment == "" {
+		fmt.Fprintln(w, "  (нет)")
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintln(w, " ", line)
+	}
+}
+
+func PrintEntryMetadata(w io.Writer, meta fsops.EntryMetadata) {
+	fmt.Fprintln(w, "Имя:        ", meta.Name)
+	fmt.Fprintln(w, "Размер:     ", meta.Size, "байт, сжато", meta.CompressedSize)
+	fmt.Fprintln(w, "Метод:      ", meta.Method)
+	if meta.Encryption != "" {
+		fmt.Fprintln(w, "Шифрование: ", meta.Encryption)
+	}
+	fmt.Fprintf(w, "CRC-32:      %08x\n", meta.CRC32)
+	precision := "до 2 секунд, поле MS-DOS"
+	if meta.PreciseTime {
+		precision = "до секунды, расширенная метка"
+	}
+	fmt.Fprintf(w, "Изменён:     %s (%s)\n", meta.Modified.Local().Format("2006-01-02 15:04:05"), precision)
+	fmt.Fprintln(w, "Права:      ", meta.Mode)
+	if meta.UID >= 0 {
+		fmt.Fprintf(w, "Владелец:    uid %d, gid %d\n", meta.UID, meta.GID)
+	}
+	fmt.Fprintln(w, "Создан в:   ", meta.Creator)
+
+	ids := make([]string, 0, len(meta.Extra))
+	for _, id := range meta.Extra {
+		ids = append(ids, fmt.Sprintf("0x%04x", id))
+	}
+	if len(ids) > 0 {
+		fmt.Fprintln(w, "Доп. поля:  ", strings.Join(ids, " "))
+	}
+	fmt.Fprintln(w, "Комментарий:")
+	printComment(w, meta.Comment)
+}
@@ -0,0 +1,77 @@
+package zipmenu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/util"
+	"github.com/inancgumus/screen"
+)
+
+func testArchive(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Проверка целостности архива ---")
+	archive, ok := openArchive(scanner)
+	if !ok {
+		return
+	}
+
+	entries, err := archive.List()
+	if err != nil {
+		fmt.Println("Ошибка при чтении архива:", err)
+		util.Pause()
+		return
+	}
+
+	report, err := archive.Test(askPassword(scanner, entries))
+	if err != nil {
+		fmt.Println("Ошибка при проверке архива:", err)
+		util.Pause()
+		return
+	}
+
+	PrintTestReport(os.Stdout, report)
+	util.Pause()
+}
+
+// PrintTestReport выводит результат проверки каждой записи и ошибки
+// архива в целом.
+func PrintTestReport(w io.Writer, report fsops.ArchiveTestReport) {
+	if len(report.Entries) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Результат\tРазмер\tCRC-32\tИмя")
+		failed := 0
+		for _, entry := range report.Entries {
+			status := "OK"
+			if !entry.OK() {
+				status = "ОШИБКА"
+				failed++
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%08x\t%s\n", status, entry.Size, entry.CRC32, entry.Name)
+		}
+		tw.Flush()
+
+		for _, entry := range report.Entries {
+			if !entry.OK() {
+				fmt.Fprintf(w, "  %s: %v\n", entry.Name, entry.Err)
+			}
+		}
+		fmt.Fprintf(w, "Проверено записей: %d, с ошибками: %d\n", len(report.Entries), failed)
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Fprintln(w, "Архив:", problem)
+	}
+
+	if report.OK() {
+		fmt.Fprintln(w, "Архив исправен.")
+	} else {
+		fmt.Fprintln(w, "Архив повреждён.")
+	}
+}
@@ -1,28 +1,40 @@
 package zipmenu
 
 import (
-	"archive/zip"
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
+	"strings"
 
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/trash"
 	"github.com/AlanMute/file-manager/pkg/util"
 	"github.com/inancgumus/screen"
 )
 
+const archivePrompt = "Введите имя архива (.zip можно не указывать; также .tar, .tar.gz, .tar.bz2, .tar.xz, .tar.zst): "
+
 func ShowMenu(scanner *bufio.Scanner) {
 	for {
 		screen.Clear()
 		screen.MoveTopLeft()
 
-		fmt.Println("--- Работа с zip архивами ---")
+		fmt.Println("--- Работа с архивами ---")
 		fmt.Println("1. Создать zip архив")
-		fmt.Println("2. Добавить файл в zip архив")
-		fmt.Println("3. Разархивировать файл")
-		fmt.Println("4. Удалить архив")
-		fmt.Println("5. Назад в главное меню")
+		fmt.Println("2. Добавить файл или папку в zip архив")
+		fmt.Println("3. Показать содержимое архива")
+		fmt.Println("4. Разархивировать файл")
+		fmt.Println("5. Разархивировать всё или по шаблону")
+		fmt.Println("6. Удалить записи из zip архива")
+		fmt.Println("7. Переименовать запись в zip архиве")
+		fmt.Println("8. Проверить целостность архива")
+		fmt.Println("9. Комментарии и метаданные zip архива")
+		fmt.Println("10. Открыть zip архив как папку")
+		fmt.Println("11. Удалить архив")
+		fmt.Println("12. Назад в главное меню")
 
 		fmt.Print("Выберите действие: ")
 		scanner.Scan()
@@ -34,10 +46,24 @@ func ShowMenu(scanner *bufio.Scanner) {
 		case "2":
 			addFileToZip(scanner)
 		case "3":
-			extractZip(scanner)
+			listZip(scanner)
 		case "4":
-			deleteZipAndFile(scanner)
+			extractZip(scanner)
 		case "5":
+			extractAll(scanner)
+		case "6":
+			removeEntries(scanner)
+		case "7":
+			renameEntry(scanner)
+		case "8":
+			testArchive(scanner)
+		case "9":
+			editMetadata(scanner)
+		case "10":
+			browseArchive(scanner)
+		case "11":
+			deleteZipAndFile(scanner)
+		case "12":
 			return // Возврат в главное меню
 		default:
 			fmt.Println("Неверный выбор, попробуйте снова.")
@@ -45,179 +71,265 @@ func ShowMenu(scanner *bufio.Scanner) {
 	}
 }
 
-func createZipArchive(scanner *bufio.Scanner) {
-	screen.Clear()
-	screen.MoveTopLeft()
-
-	fmt.Println("--- Создание zip архива ---")
-	fmt.Print("Введите имя архива для создания (без .zip): ")
+// openArchive спрашивает имя архива и открывает его, определяя формат по
+// содержимому. Если файла с введённым именем нет, к нему дописывается
+// ".zip". При ошибке она выводится и ok равно false.
+func openArchive(scanner *bufio.Scanner) (archive fsops.Archive, ok bool) {
+	fmt.Print(archivePrompt)
 	scanner.Scan()
 	archiveName := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	archivePath, err := util.ResolvePath(scanner, archiveName)
+	if info, statErr := os.Stat(archivePath); err != nil || statErr != nil || !info.Mode().IsRegular() {
+		archivePath, err = util.ResolvePath(scanner, fsops.ArchiveName(archiveName))
+	}
+	if err == nil {
+		archive, err = fsops.OpenArchive(archivePath)
+	}
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при открытии архива:", err)
 		util.Pause()
-		return
+		return nil, false
 	}
-	archivePath := filepath.Join(documentsPath, archiveName+".zip")
+	return archive, true
+}
 
-	zipFile, err := os.Create(archivePath)
-	if err != nil {
-		fmt.Println("Ошибка при создании архива:", err)
+// openZipArchive открывает архив как openArchive, но принимает только
+// zip: изменять записи в архивах tar нельзя.
+func openZipArchive(scanner *bufio.Scanner) (archivePath string, ok bool) {
+	archive, ok := openArchive(scanner)
+	if !ok {
+		return "", false
+	}
+	if archive.Format() != fsops.FormatZip {
+		fmt.Printf("Операция поддерживается только для zip архивов, а %s — архив %s.\n", archive.Path(), archive.Format())
 		util.Pause()
-		return
+		return "", false
 	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	return archive.Path(), true
+}
 
-	fmt.Println("Архив создан по пути:", archivePath)
-	util.Pause()
+// askPassword спрашивает пароль, если среди записей есть зашифрованные.
+func askPassword(scanner *bufio.Scanner, entries []fsops.ArchiveEntry) string {
+	for _, entry := range entries {
+		if entry.Encryption != "" {
+			return util.AskPassword(scanner, "Архив зашифрован. Введите пароль: ")
+		}
+	}
+	return ""
 }
 
-func addFileToZip(scanner *bufio.Scanner) {
+func createZipArchive(scanner *bufio.Scanner) {
 	screen.Clear()
 	screen.MoveTopLeft()
 
-	fmt.Println("--- Добавление файла в zip архив ---")
-	fmt.Print("Введите имя архива (без .zip): ")
+	fmt.Println("--- Создание zip архива ---")
+	fmt.Print(archivePrompt)
 	scanner.Scan()
 	archiveName := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	archivePath, err := util.ResolvePath(scanner, fsops.ArchiveName(archiveName))
+	var archive fsops.Archive
+	if err == nil {
+		archive, err = fsops.CreateArchive(archivePath)
+	}
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при создании архива:", err)
 		util.Pause()
 		return
 	}
-	archivePath := filepath.Join(documentsPath, archiveName+".zip")
 
-	zipFile, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		fmt.Println("Ошибка при открытии архива:", err)
-		util.Pause()
+	fmt.Printf("Архив %s создан по пути: %s\n", archive.Format(), archivePath)
+	util.Pause()
+}
+
+func addFileToZip(scanner *bufio.Scanner) {
+	screen.Clear()
+	screen.MoveTopLeft()
+
+	fmt.Println("--- Добавление в архив ---")
+	archive, ok := openArchive(scanner)
+	if !ok {
 		return
 	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
 
-	fmt.Print("Введите имя файла для добавления в архив (полный путь или в папке 'Документы'): ")
+	fmt.Print("Введите имя файла или папки для добавления в архив (полный путь или путь в рабочей папке): ")
 	scanner.Scan()
 	fileName := scanner.Text()
-	filePath := filepath.Join(documentsPath, fileName)
 
-	fileToZip, err := os.Open(filePath)
-	if err != nil {
-		fmt.Println("Ошибка при открытии файла для архивации:", err)
-		util.Pause()
-		return
+	var opts fsops.AddOptions
+	fmt.Print("Включать только файлы по шаблонам через пробел (например *.go docs/**, пусто — все): ")
+	scanner.Scan()
+	opts.Include = strings.Fields(scanner.Text())
+
+	fmt.Print("Исключить файлы по шаблонам через пробел (пусто — ничего не исключать): ")
+	scanner.Scan()
+	opts.Exclude = strings.Fields(scanner.Text())
+
+	fmt.Print("Символические ссылки: 1 — сохранить как ссылки, 2 — следовать, 3 — пропустить [1]: ")
+	scanner.Scan()
+	switch strings.TrimSpace(scanner.Text()) {
+	case "2":
+		opts.Symlinks = fsops.SymlinkFollow
+	case "3":
+		opts.Symlinks = fsops.SymlinkSkip
 	}
-	defer fileToZip.Close()
 
-	info, err := fileToZip.Stat()
-	if err != nil {
-		fmt.Println("Ошибка при получении информации о файле:", err)
+	if err := askCompression(scanner, archive.Format(), &opts); err != nil {
+		fmt.Println(err)
 		util.Pause()
 		return
 	}
 
-	header, err := zip.FileInfoHeader(info)
+	if archive.Format() == fsops.FormatZip && util.Confirm(scanner, "Зашифровать добавляемые файлы (AES-256)?") {
+		password, err := util.AskNewPassword(scanner)
+		if err != nil {
+			fmt.Println(err)
+			util.Pause()
+			return
+		}
+		opts.Password = password
+	}
+
+	filePath, err := util.ResolvePath(scanner, fileName)
 	if err != nil {
-		fmt.Println("Ошибка при создании заголовка для архива:", err)
+		fmt.Println(err)
 		util.Pause()
 		return
 	}
 
-	writer, err := zipWriter.CreateHeader(header)
-	if err != nil {
-		fmt.Println("Ошибка при создании записи в архиве:", err)
+	ctx, stop := util.WithInterrupt()
+	defer stop()
+	opts.Ctx = ctx
+	opts.Progress = util.NewProgressBar(os.Stdout).Update
+
+	summary, err := archive.Add([]string{filePath}, opts)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Добавление прервано, архив не изменён.")
 		util.Pause()
 		return
 	}
-
-	_, err = io.Copy(writer, fileToZip)
 	if err != nil {
-		fmt.Println("Ошибка при копировании файла в архив:", err)
+		fmt.Println("Ошибка при добавлении в архив:", err)
 		util.Pause()
 		return
 	}
 
-	fmt.Println("Файл добавлен в архив:", archivePath)
+	fmt.Println("Архив обновлён:", archive.Path())
+	PrintAddSummary(os.Stdout, summary)
 	util.Pause()
 }
 
+// askCompression спрашивает метод и уровень сжатия. Для tar метод задан
+// форматом архива, поэтому спрашивается только уровень.
+func askCompression(scanner *bufio.Scanner, format fsops.Format, opts *fsops.AddOptions) error {
+	method := "deflate"
+	if format == fsops.FormatZip {
+		fmt.Print("Сжатие: 1 — deflate, 2 — без сжатия (store), 3 — zstd [1]: ")
+		scanner.Scan()
+		switch strings.TrimSpace(scanner.Text()) {
+		case "2":
+			method = "store"
+		case "3":
+			method = "zstd"
+		}
+	}
+
+	if method != "store" {
+		fmt.Print("Уровень сжатия от 0 (быстрее) до 9 (сильнее), пусто — по умолчанию: ")
+		scanner.Scan()
+		if level := strings.TrimSpace(scanner.Text()); level != "" {
+			method += ":" + level
+		}
+	}
+	compression, err := fsops.ParseCompression(method)
+	if err != nil {
+		return err
+	}
+	opts.Compression = &compression
+
+	if format != fsops.FormatZip {
+		return nil
+	}
+	fmt.Print("Сжатие отдельных файлов через пробел (например *.log=zstd:9 *.bin=store, пусто — нет; jpg, png, zip, gz, mp4 и т. п. не сжимаются): ")
+	scanner.Scan()
+	for _, text := range strings.Fields(scanner.Text()) {
+		rule, err := fsops.ParseCompressionRule(text)
+		if err != nil {
+			return err
+		}
+		opts.Rules = append(opts.Rules, rule)
+	}
+	return nil
+}
+
+func PrintAddSummary(w io.Writer, summary fsops.AddSummary) {
+	for _, name := range summary.Added {
+		fmt.Fprintln(w, "  +", name)
+	}
+	fmt.Fprintf(w, "Добавлено файлов: %d (%d байт), папок: %d, ссылок: %d, пропущено: %d\n",
+		summary.Files, summary.Bytes, summary.Dirs, summary.Symlinks, summary.Skipped)
+}
+
 func extractZip(scanner *bufio.Scanner) {
 	screen.Clear()
 	screen.MoveTopLeft()
 
 	fmt.Println("--- Разархивирование файла из архива ---")
-	fmt.Print("Введите имя архива для разархивирования (без .zip): ")
-	scanner.Scan()
-	archiveName := scanner.Text()
-
-	documentsPath, err := util.GetDocumentsPath()
-	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
-		util.Pause()
+	archive, ok := openArchive(scanner)
+	if !ok {
 		return
 	}
-	archivePath := filepath.Join(documentsPath, archiveName+".zip")
 
-	zipReader, err := zip.OpenReader(archivePath)
+	entries, err := archive.List()
 	if err != nil {
 		fmt.Println("Ошибка при открытии архива:", err)
 		util.Pause()
 		return
 	}
-	defer zipReader.Close()
 
 	fmt.Println("Файлы в архиве:")
-	for _, file := range zipReader.File {
-		fmt.Println(file.Name)
+	for _, entry := range entries {
+		fmt.Println(entry.Name)
 	}
 
+	opts := fsops.ExtractOptions{Limits: fsops.DefaultExtractLimits}
+	opts.Password = askPassword(scanner, entries)
+
 	fmt.Print("\nВведите имя файла для разархивирования: ")
 	scanner.Scan()
 	fileName := scanner.Text()
 
-	for _, file := range zipReader.File {
-		if file.Name == fileName {
-			archivedFile, err := file.Open()
-			if err != nil {
-				fmt.Println("Ошибка при открытии файла из архива:", err)
-				util.Pause()
-				return
-			}
-			defer archivedFile.Close()
-
-			content, err := io.ReadAll(archivedFile)
-			if err != nil {
-				fmt.Println("Ошибка при чтении файла из архива:", err)
-				util.Pause()
-				return
-			}
-
-			extractedFilePath := filepath.Join(documentsPath, fileName)
-			err = os.WriteFile(extractedFilePath, content, 0644)
-			if err != nil {
-				fmt.Println("Ошибка при сохранении файла:", err)
-				util.Pause()
-				return
-			}
-
-			fmt.Println("Файл разархивирован и сохранен по пути:", extractedFilePath)
-			fmt.Println("Содержимое файла", fileName, ":")
-			fmt.Println(string(content))
-			util.Pause()
-			return
-		}
+	extractedFilePath, err := util.ResolvePath(scanner, fileName)
+	if err == nil {
+		err = archive.ExtractEntry(fileName, extractedFilePath, opts)
+	}
+	if errors.Is(err, fsops.ErrEntryNotFound) {
+		fmt.Println("Файл", fileName, "не найден в архиве.")
+		util.Pause()
+		return
+	}
+	if errors.Is(err, fsops.ErrWrongPassword) {
+		fmt.Println("Неверный пароль.")
+		util.Pause()
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при разархивировании файла:", err)
+		util.Pause()
+		return
 	}
 
-	fmt.Println("Файл", fileName, "не найден в архиве.")
+	content, err := fsops.ReadFile(extractedFilePath)
+	if err != nil {
+		fmt.Println("Ошибка при чтении файла:", err)
+		util.Pause()
+		return
+	}
+
+	fmt.Println("Файл разархивирован и сохранен по пути:", extractedFilePath)
+	fmt.Println("Содержимое файла", fileName, ":")
+	fmt.Println(string(content))
 	util.Pause()
 }
 
@@ -226,24 +338,28 @@ func deleteZipAndFile(scanner *bufio.Scanner) {
 	screen.MoveTopLeft()
 
 	fmt.Println("--- Удаление файла и архива ---")
-	fmt.Print("Введите имя архива для удаления (без .zip): ")
+	fmt.Print(archivePrompt)
 	scanner.Scan()
 	archiveName := scanner.Text()
 
-	documentsPath, err := util.GetDocumentsPath()
+	archivePath, err := util.ResolvePath(scanner, fsops.ArchiveName(archiveName))
 	if err != nil {
-		fmt.Println("Ошибка при получении пути до папки документов:", err)
+		fmt.Println("Ошибка при удалении архива:", err)
+		util.Pause()
+		return
+	}
+	if !util.Confirm(scanner, "Переместить "+archivePath+" в корзину?") {
+		fmt.Println("Удаление отменено.")
 		util.Pause()
 		return
 	}
-	archivePath := filepath.Join(documentsPath, archiveName+".zip")
 
-	err = os.Remove(archivePath)
-	if err != nil {
+	if err := trash.Put(archivePath); err != nil {
 		fmt.Println("Ошибка при удалении архива:", err)
 		util.Pause()
 		return
 	}
 
+	fmt.Println("Архив перемещён в корзину:", archivePath)
 	util.Pause()
 }
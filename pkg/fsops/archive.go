@@ -0,0 +1,211 @@
+package fsops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatZip
+	FormatTar
+	FormatTarGz
+	FormatTarBz2
+	FormatTarXz
+	FormatTarZst
+)
+
+var formatNames = map[Format]string{
+	FormatZip:    "zip",
+	FormatTar:    "tar",
+	FormatTarGz:  "tar.gz",
+	FormatTarBz2: "tar.bz2",
+	FormatTarXz:  "tar.xz",
+	FormatTarZst: "tar.zst",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+var formatSuffixes = []struct {
+	suffix string
+	format Format
+}{
+	{".zip", FormatZip},
+	{".tar.gz", FormatTarGz},
+	{".tgz", FormatTarGz},
+	{".tar.bz2", FormatTarBz2},
+	{".tbz2", FormatTarBz2},
+	{".tbz", FormatTarBz2},
+	{".tar.xz", FormatTarXz},
+	{".txz", FormatTarXz},
+	{".tar.zst", FormatTarZst},
+	{".tzst", FormatTarZst},
+	{".tar", FormatTar},
+}
+
+// FormatFromName определяет формат по расширению имени. Используется для
+// новых архивов; формат существующих определяет DetectFormat.
+func FormatFromName(name string) Format {
+	lower := strings.ToLower(name)
+	for _, s := range formatSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format
+		}
+	}
+	return FormatUnknown
+}
+
+// ArchiveName дописывает ".zip" к имени без известного расширения архива.
+func ArchiveName(name string) string {
+	if FormatFromName(name) != FormatUnknown {
+		return name
+	}
+	return name + ".zip"
+}
+
+// DetectFormat определяет формат архива по сигнатуре в начале файла,
+// не глядя на расширение. Сжатый поток считается сжатым tar-архивом.
+func DetectFormat(archivePath string) (Format, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return FormatUnknown, wrap("открытие архива", archivePath, err)
+	}
+	defer file.Close()
+
+	var head [512]byte
+	n, err := io.ReadFull(file, head[:])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return FormatUnknown, wrap("открытие архива", archivePath, err)
+	}
+
+	if format := detectFormat(head[:n]); format != FormatUnknown {
+		return format, nil
+	}
+	return FormatUnknown, wrap("открытие архива", archivePath,
+		fmt.Errorf("%w: неизвестный формат архива", ErrUnsupported))
+}
+
+func detectFormat(head []byte) Format {
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")),
+		bytes.HasPrefix(head, []byte("PK\x05\x06")),
+		bytes.HasPrefix(head, []byte("PK\x07\x08")):
+		return FormatZip
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return FormatTarGz
+	case bytes.HasPrefix(head, []byte("BZh")):
+		return FormatTarBz2
+	case bytes.HasPrefix(head, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return FormatTarXz
+	case bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return FormatTarZst
+	case len(head) >= 262 && bytes.Equal(head[257:262], []byte("ustar")):
+		return FormatTar
+	case len(head) == 512 && bytes.Count(head, []byte{0}) == 512:
+		// Пустой tar-архив состоит из одних нулевых блоков.
+		return FormatTar
+	}
+	return FormatUnknown
+}
+
+// Archive — архив на диске в одном из поддерживаемых форматов. Операции
+// над ним одинаковы для zip и tar; формат влияет только на то, что
+// показывает листинг, и на доступность записи (bzip2 только читается).
+type Archive interface {
+	Path() string
+	Format() Format
+	List() ([]ArchiveEntry, error)
+	Add(paths []string, opts AddOptions) (AddSummary, error)
+	Extract(destDir string, opts ExtractOptions) (ExtractSummary, error)
+	ExtractEntry(name, destPath string, opts ExtractOptions) error
+	// Test проверяет архив; password нужен только для зашифрованных
+	// записей zip.
+	Test(password string) (ArchiveTestReport, error)
+}
+
+// OpenArchive открывает существующий архив, определяя формат по
+// содержимому файла.
+func OpenArchive(archivePath string) (Archive, error) {
+	format, err := DetectFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	return newArchive(archivePath, format), nil
+}
+
+// CreateArchive создаёт пустой архив в формате, заданном расширением
+// имени; имя без известного расширения создаёт zip.
+func CreateArchive(archivePath string) (Archive, error) {
+	format := FormatFromName(archivePath)
+	if format == FormatUnknown {
+		format = FormatZip
+	}
+
+	var err error
+	if format == FormatZip {
+		err = CreateZip(archivePath)
+	} else {
+		err = createTar(archivePath, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newArchive(archivePath, format), nil
+}
+
+func newArchive(archivePath string, format Format) Archive {
+	if format == FormatZip {
+		return zipArchive{path: archivePath}
+	}
+	return tarArchive{path: archivePath, format: format}
+}
+
+type zipArchive struct {
+	path string
+}
+
+func (a zipArchive) Path() string   { return a.path }
+func (a zipArchive) Format() Format { return FormatZip }
+
+func (a zipArchive) List() ([]ArchiveEntry, error) {
+	return ZipListing(a.path)
+}
+
+func (a zipArchive) Add(paths []string, opts AddOptions) (AddSummary, error) {
+	return AddToZip(a.path, paths, opts)
+}
+
+func (a zipArchive) Extract(destDir string, opts ExtractOptions) (ExtractSummary, error) {
+	return ExtractZip(a.path, destDir, opts)
+}
+
+func (a zipArchive) ExtractEntry(name, destPath string, opts ExtractOptions) error {
+	return ExtractZipEntry(a.path, name, destPath, opts)
+}
+
+func (a zipArchive) Test(password string) (ArchiveTestReport, error) {
+	return TestZip(a.path, password)
+}
+
+// TrimArchiveExt убирает из имени известное расширение архива:
+// "build.tar.gz" превращается в "build".
+func TrimArchiveExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range formatSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return name[:len(name)-len(s.suffix)]
+		}
+	}
+	return name
+}
@@ -0,0 +1,98 @@
+package fsops
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic записывает файл через временный файл в той же папке и
+// переименование, так что при сбое на месте остаётся прежнее содержимое.
+// Права существующего файла сохраняются, новый файл получает 0644.
+func WriteAtomic(path string, write func(w io.Writer) error) error {
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// replaceWith вызывает write для временного пути в той же папке, что и
+// dst, и ставит результат на место dst. Если write не удался, dst
+// остаётся прежним.
+func replaceWith(dst string, write func(tmp string) error) error {
+	dir, err := os.MkdirTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, filepath.Base(dst))
+	if err := write(tmp); err != nil {
+		return err
+	}
+	return replacePath(tmp, dst)
+}
+
+// replacePath переносит src на место dst. Файл поверх файла rename
+// заменяет сам; папку же сначала убираем во временную папку рядом, чтобы
+// вернуть её, если перенос src не удастся.
+func replacePath(src, dst string) error {
+	dstInfo, err := os.Lstat(dst)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Rename(src, dst)
+	} else if err != nil {
+		return err
+	}
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !dstInfo.IsDir() && !srcInfo.IsDir() {
+		return os.Rename(src, dst)
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.old")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	old := filepath.Join(dir, filepath.Base(dst))
+	if err := os.Rename(dst, old); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return err
+	}
+	return nil
+}
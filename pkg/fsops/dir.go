@@ -0,0 +1,109 @@
+package fsops
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ListDir возвращает сведения о содержимом папки: сначала папки, затем
+// остальные файлы, каждая группа по имени. Символические ссылки не раскрываются.
+func ListDir(path string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, wrap("чтение папки", path, err)
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, wrap("чтение папки", filepath.Join(path, entry.Name()), err)
+		}
+		infos = append(infos, info)
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+func Mkdir(path string) error {
+	return wrap("создание папки", path, os.Mkdir(path, 0755))
+}
+
+// Rmdir удаляет папку. Непустая папка удаляется только при recursive,
+// иначе возвращается ErrNotEmpty.
+func Rmdir(path string, recursive bool) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return wrap("удаление папки", path, err)
+	}
+	if !info.IsDir() {
+		return wrap("удаление папки", path, ErrNotDir)
+	}
+
+	if recursive {
+		return wrap("удаление папки", path, os.RemoveAll(path))
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return wrap("удаление папки", path, err)
+	}
+	if len(entries) > 0 {
+		return wrap("удаление папки", path, ErrNotEmpty)
+	}
+	return wrap("удаление папки", path, os.Remove(path))
+}
+
+type TreeNode struct {
+	Info     fs.FileInfo
+	Children []*TreeNode
+	Err      error
+}
+
+// Tree строит дерево папки глубиной не больше maxDepth уровней
+// (0 — без ограничения). Ошибки чтения вложенных папок сохраняются
+// в Err соответствующего узла, а не прерывают обход.
+func Tree(path string, maxDepth int) (*TreeNode, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, wrap("построение дерева", path, err)
+	}
+	if !info.IsDir() {
+		return nil, wrap("построение дерева", path, ErrNotDir)
+	}
+
+	root := &TreeNode{Info: info}
+	fillTree(root, path, 1, maxDepth)
+	return root, nil
+}
+
+func fillTree(node *TreeNode, path string, depth, maxDepth int) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+
+	infos, err := ListDir(path)
+	if err != nil {
+		node.Err = err
+		return
+	}
+
+	for _, info := range infos {
+		child := &TreeNode{Info: info}
+		if info.IsDir() {
+			fillTree(child, filepath.Join(path, info.Name()), depth+1, maxDepth)
+		}
+		node.Children = append(node.Children, child)
+	}
+}
+
+func sortInfos(infos []fs.FileInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].IsDir() != infos[j].IsDir() {
+			return infos[i].IsDir()
+		}
+		return infos[i].Name() < infos[j].Name()
+	})
+}
@@ -0,0 +1,62 @@
+package fsops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+var (
+	ErrNotFound       = errors.New("файл не найден")
+	ErrAlreadyExists  = errors.New("файл уже существует")
+	ErrPermission     = errors.New("недостаточно прав")
+	ErrNotDir         = errors.New("не является папкой")
+	ErrNotEmpty       = errors.New("папка не пуста")
+	ErrSameFile       = errors.New("источник и назначение совпадают")
+	ErrIntoItself     = errors.New("нельзя скопировать папку внутрь самой себя")
+	ErrContainsSource = errors.New("нельзя заменить папку, в которой находится источник")
+	ErrUnsupported    = errors.New("неподдерживаемый тип файла")
+	ErrUnsafePath     = errors.New("путь в архиве выходит за пределы папки назначения")
+	ErrLimitExceeded  = errors.New("превышено ограничение на распаковку")
+	ErrEntryNotFound  = errors.New("файл не найден в архиве")
+	ErrInvalidName    = errors.New("недопустимое имя")
+	ErrNeedPassword   = errors.New("запись зашифрована, нужен пароль")
+	ErrWrongPassword  = errors.New("неверный пароль")
+)
+
+// Error описывает неудачную операцию над файлом. Err можно проверять
+// через errors.Is на ErrNotFound, ErrPermission и другие ошибки пакета.
+type Error struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func wrap(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		err = fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, fs.ErrExist):
+		err = fmt.Errorf("%w: %w", ErrAlreadyExists, err)
+	case errors.Is(err, fs.ErrPermission):
+		err = fmt.Errorf("%w: %w", ErrPermission, err)
+	}
+	return &Error{Op: op, Path: path, Err: err}
+}
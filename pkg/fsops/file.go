@@ -0,0 +1,36 @@
+package fsops
+
+import "os"
+
+func CreateFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return wrap("создание файла", path, err)
+	}
+	return wrap("создание файла", path, file.Close())
+}
+
+func AppendFile(path, text string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return wrap("открытие файла", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(text); err != nil {
+		return wrap("запись в файл", path, err)
+	}
+	return wrap("запись в файл", path, file.Close())
+}
+
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrap("чтение файла", path, err)
+	}
+	return data, nil
+}
+
+func Remove(path string) error {
+	return wrap("удаление файла", path, os.Remove(path))
+}
@@ -0,0 +1,14 @@
+package fsops
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func WriteJSON(path string, v any) error {
+	fileData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return wrap("сериализация в JSON", path, err)
+	}
+	return wrap("запись JSON в файл", path, os.WriteFile(path, fileData, 0644))
+}
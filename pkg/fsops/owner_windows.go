@@ -0,0 +1,8 @@
+package fsops
+
+import "io/fs"
+
+// В Windows у файлов нет числовых владельцев Unix.
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
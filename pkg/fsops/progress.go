@@ -0,0 +1,134 @@
+package fsops
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressInfo описывает ход долгой операции. Total-поля равны нулю,
+// если объём работы заранее неизвестен.
+type ProgressInfo struct {
+	Bytes      int64
+	TotalBytes int64
+	Files      int
+	TotalFiles int
+	// Name — файл, который обрабатывается сейчас.
+	Name string
+	// Done выставляется в последнем вызове, когда операция завершена
+	// или прервана.
+	Done bool
+}
+
+// progressInterval ограничивает частоту вызова обработчика прогресса.
+const progressInterval = 100 * time.Millisecond
+
+// tracker считает обработанные байты и файлы и проверяет отмену
+// операции. Его можно использовать из нескольких горутин.
+type tracker struct {
+	ctx  context.Context
+	fn   func(ProgressInfo)
+	mu   sync.Mutex
+	info ProgressInfo
+	last time.Time
+}
+
+func newTracker(ctx context.Context, fn func(ProgressInfo), totalBytes int64, totalFiles int) *tracker {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &tracker{ctx: ctx, fn: fn, info: ProgressInfo{TotalBytes: totalBytes, TotalFiles: totalFiles}}
+}
+
+// err возвращает context.Canceled или context.DeadlineExceeded, если
+// операцию пора прервать.
+func (t *tracker) err() error {
+	return t.ctx.Err()
+}
+
+func (t *tracker) enabled() bool {
+	return t.fn != nil
+}
+
+func (t *tracker) begin(name string) {
+	t.update(func(info *ProgressInfo) { info.Name = name })
+}
+
+func (t *tracker) addBytes(n int64) {
+	t.update(func(info *ProgressInfo) { info.Bytes += n })
+}
+
+func (t *tracker) fileDone() {
+	t.update(func(info *ProgressInfo) { info.Files++ })
+}
+
+// skip засчитывает работу, выполненную без чтения данных, например
+// перемещение переименованием.
+func (t *tracker) skip(bytes int64, files int) {
+	t.update(func(info *ProgressInfo) {
+		info.Bytes += bytes
+		info.Files += files
+	})
+}
+
+func (t *tracker) update(change func(info *ProgressInfo)) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	change(&t.info)
+	if now := time.Now(); now.Sub(t.last) >= progressInterval {
+		t.last = now
+		t.fn(t.info)
+	}
+}
+
+// finish сообщает итог операции независимо от частоты обновлений.
+func (t *tracker) finish() {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.info.Name = ""
+	t.info.Done = true
+	t.fn(t.info)
+}
+
+// reader учитывает прочитанные байты и прерывает чтение при отмене.
+func (t *tracker) reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, t: t, count: true}
+}
+
+// ctxReader только прерывает чтение при отмене, например при
+// копировании прежних записей архива, которые не входят в объём работы.
+func (t *tracker) ctxReader(r io.Reader) io.Reader {
+	return &progressReader{r: r, t: t}
+}
+
+type progressReader struct {
+	r     io.Reader
+	t     *tracker
+	count bool
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.t.err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	if p.count {
+		p.t.addBytes(int64(n))
+	}
+	return n, err
+}
+
+// isCanceled сообщает, что операция прервана через контекст.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
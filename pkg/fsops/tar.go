@@ -0,0 +1,483 @@
+package fsops
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// tarArchive — tar-архив, возможно сжатый целиком. В отличие от zip
+// у него нет каталога, поэтому любая операция читает поток с начала, а
+// добавление пересобирает и пережимает архив полностью.
+type tarArchive struct {
+	path   string
+	format Format
+}
+
+func (a tarArchive) Path() string   { return a.path }
+func (a tarArchive) Format() Format { return a.format }
+
+func createTar(archivePath string, format Format) error {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return wrap("создание архива", archivePath, err)
+	}
+	defer file.Close()
+
+	compressor, err := compressWriter(file, format, DefaultCompression.Level)
+	if err == nil {
+		err = tar.NewWriter(compressor).Close()
+	}
+	if err == nil {
+		err = compressor.Close()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(archivePath)
+		return wrap("создание архива", archivePath, err)
+	}
+	return wrap("создание архива", archivePath, file.Close())
+}
+
+// compressWriter сжимает поток tar. Уровень от 0 до 9 учитывается для
+// gzip и zstd; -1 — уровень по умолчанию.
+func compressWriter(w io.Writer, format Format, level int) (io.WriteCloser, error) {
+	switch format {
+	case FormatTar:
+		return nopWriteCloser{w}, nil
+	case FormatTarGz:
+		return gzip.NewWriterLevel(w, deflateLevel(level))
+	case FormatTarXz:
+		return xz.NewWriter(w)
+	case FormatTarZst:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel(level)))
+	default:
+		return nil, fmt.Errorf("%w: запись в формате %s", ErrUnsupported, format)
+	}
+}
+
+func decompressReader(r io.Reader, format Format) (io.ReadCloser, error) {
+	switch format {
+	case FormatTar:
+		return io.NopCloser(r), nil
+	case FormatTarGz:
+		return gzip.NewReader(r)
+	case FormatTarBz2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case FormatTarXz:
+		reader, err := xz.NewReader(r)
+		return io.NopCloser(reader), err
+	case FormatTarZst:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("%w: формат %s", ErrUnsupported, format)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// tarStream — открытый для чтения архив: файл, распаковщик и tar поверх.
+type tarStream struct {
+	file         *os.File
+	decompressed io.ReadCloser
+	*tar.Reader
+}
+
+// open открывает поток архива. Если maxRatio больше нуля, поток
+// прерывается с ErrLimitExceeded, как только распакованных данных
+// становится больше сжатых в maxRatio раз: у сжатого tar степень сжатия
+// отдельных записей неизвестна, поэтому проверяется поток целиком.
+func (a tarArchive) open(maxRatio float64) (*tarStream, error) {
+	file, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := &countingReader{r: file}
+	decompressed, err := decompressReader(compressed, a.format)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	var r io.Reader = decompressed
+	if maxRatio > 0 && a.format != FormatTar {
+		r = &ratioReader{r: decompressed, compressed: compressed, maxRatio: maxRatio}
+	}
+	return &tarStream{file: file, decompressed: decompressed, Reader: tar.NewReader(r)}, nil
+}
+
+func (s *tarStream) Close() error {
+	s.decompressed.Close()
+	return s.file.Close()
+}
+
+// next возвращает следующую запись, пропуская служебные заголовки pax.
+func (s *tarStream) next() (*tar.Header, error) {
+	for {
+		header, err := s.Next()
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeXGlobalHeader {
+			return header, nil
+		}
+	}
+}
+
+func (a tarArchive) List() ([]ArchiveEntry, error) {
+	stream, err := a.open(0)
+	if err != nil {
+		return nil, wrap("открытие архива", a.path, err)
+	}
+	defer stream.Close()
+
+	var entries []ArchiveEntry
+	for {
+		header, err := stream.next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, wrap("чтение архива", a.path, err)
+		}
+
+		// Сжимается весь поток, а не отдельные записи, поэтому
+		// CompressedSize записи совпадает с её размером.
+		entries = append(entries, ArchiveEntry{
+			Name:           header.Name,
+			Size:           uint64(header.Size),
+			CompressedSize: uint64(header.Size),
+			Method:         a.format.String(),
+			Modified:       header.ModTime,
+			Mode:           tarMode(header),
+		})
+	}
+}
+
+// Add пересобирает архив во временном файле: прежние записи копируются,
+// кроме заменяемых, новые дописываются в конец.
+func (a tarArchive) Add(paths []string, opts AddOptions) (AddSummary, error) {
+	if a.format == FormatTarBz2 {
+		return AddSummary{}, wrap("добавление в архив", a.path, fmt.Errorf("%w: запись в формате %s", ErrUnsupported, a.format))
+	}
+	if opts.Password != "" {
+		return AddSummary{}, wrap("добавление в архив", a.path, fmt.Errorf("%w: шифрование в формате %s", ErrUnsupported, a.format))
+	}
+
+	sources, replaced, summary, err := planAdd(a.path, paths, opts)
+	if err != nil {
+		return summary, err
+	}
+
+	stream, err := a.open(0)
+	if err != nil {
+		return summary, wrap("открытие архива", a.path, err)
+	}
+	defer stream.Close()
+
+	level := DefaultCompression.Level
+	if opts.Compression != nil {
+		level = opts.Compression.Level
+	}
+	tr := summary.tracker(opts)
+	defer tr.finish()
+
+	err = WriteAtomic(a.path, func(w io.Writer) error {
+		compressor, err := compressWriter(w, a.format, level)
+		if err != nil {
+			return err
+		}
+		tarWriter := tar.NewWriter(compressor)
+
+		for {
+			header, err := stream.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if replaced[header.Name] {
+				continue
+			}
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tarWriter, tr.ctxReader(stream)); err != nil {
+				return err
+			}
+		}
+
+		for _, source := range sources {
+			if err := writeTarEntry(tarWriter, source, tr); err != nil {
+				return fmt.Errorf("%s: %w", source.path, err)
+			}
+		}
+
+		if err := tarWriter.Close(); err != nil {
+			return err
+		}
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+		// Как в rewriteZip, архив закрывается до замены файла.
+		return stream.Close()
+	})
+	return summary, wrap("запись архива", a.path, err)
+}
+
+func writeTarEntry(dst *tar.Writer, source addSource, tr *tracker) error {
+	if err := tr.err(); err != nil {
+		return err
+	}
+
+	var link string
+	if source.info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(source.path)
+		if err != nil {
+			return err
+		}
+		link = target
+	}
+
+	header, err := tar.FileInfoHeader(source.info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = source.name
+	// Имена владельцев зависят от машины и при распаковке не нужны.
+	header.Uname, header.Gname = "", ""
+
+	if err := dst.WriteHeader(header); err != nil {
+		return err
+	}
+	if !source.info.Mode().IsRegular() {
+		if !source.info.IsDir() {
+			tr.fileDone()
+		}
+		return nil
+	}
+
+	file, err := os.Open(source.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tr.begin(source.name)
+	if _, err := io.Copy(dst, tr.reader(io.LimitReader(file, header.Size))); err != nil {
+		return err
+	}
+	tr.fileDone()
+	return nil
+}
+
+// Extract читает архив дважды: первый проход проверяет имена и лимиты
+// до записи на диск, как это делает ExtractZip, второй распаковывает.
+func (a tarArchive) Extract(destDir string, opts ExtractOptions) (ExtractSummary, error) {
+	var summary ExtractSummary
+	maxRatio := opts.Limits.MaxRatio
+
+	var entries []extractEntry
+	err := a.walk(maxRatio, func(header *tar.Header, _ io.Reader) error {
+		entries = append(entries, tarExtractEntry(header, nil))
+		return nil
+	})
+	if err != nil {
+		return summary, wrap("чтение архива", a.path, err)
+	}
+
+	selected, err := selectEntries(a.path, destDir, opts, entries, &summary)
+	if err != nil {
+		return summary, err
+	}
+	x, err := newExtractor(destDir, opts, &summary, entries, selected)
+	if err != nil {
+		return summary, err
+	}
+
+	err = a.walk(maxRatio, func(header *tar.Header, r io.Reader) error {
+		if !selected[header.Name] {
+			return nil
+		}
+		if err := x.extract(tarExtractEntry(header, r)); err != nil {
+			return wrap("распаковка", header.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return summary, x.abort(wrap("чтение архива", a.path, err))
+	}
+	x.finish()
+	return summary, nil
+}
+
+func (a tarArchive) ExtractEntry(name, destPath string, opts ExtractOptions) error {
+	found := false
+	err := a.walk(opts.Limits.MaxRatio, func(header *tar.Header, r io.Reader) error {
+		if found || header.Name != name {
+			return nil
+		}
+		found = true
+
+		entry := tarExtractEntry(header, r)
+		if !entry.mode.IsRegular() {
+			return wrap("распаковка", name, ErrUnsupported)
+		}
+		x := newEntryExtractor(opts, entry)
+		defer x.tracker.finish()
+		return wrap("распаковка", name, x.writeFile(entry, destPath, 0644))
+	})
+	if err != nil {
+		return wrap("чтение архива", a.path, err)
+	}
+	if !found {
+		return wrap("поиск в архиве", a.path, ErrEntryNotFound)
+	}
+	return nil
+}
+
+// walk вызывает fn для каждой записи; r читает данные текущей записи и
+// действителен только до возврата из fn.
+func (a tarArchive) walk(maxRatio float64, fn func(header *tar.Header, r io.Reader) error) error {
+	stream, err := a.open(maxRatio)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	for {
+		header, err := stream.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(header, stream); err != nil {
+			return err
+		}
+	}
+}
+
+func tarExtractEntry(header *tar.Header, r io.Reader) extractEntry {
+	entry := extractEntry{
+		name:       header.Name,
+		mode:       tarMode(header),
+		modified:   header.ModTime,
+		size:       uint64(max(header.Size, 0)),
+		compressed: -1,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(r), nil
+		},
+	}
+	if header.Typeflag == tar.TypeSymlink {
+		entry.linkTarget = header.Linkname
+	}
+	return entry
+}
+
+// tarMode возвращает режим записи. Жёсткие ссылки помечаются как
+// нестандартные файлы: их данных в архиве нет, и распаковка их пропускает.
+func tarMode(header *tar.Header) fs.FileMode {
+	mode := header.FileInfo().Mode()
+	if header.Typeflag == tar.TypeLink {
+		mode = mode.Perm() | fs.ModeIrregular
+	}
+	return mode
+}
+
+// Test читает архив целиком. Контрольных сумм данных в tar нет, поэтому
+// проверяются размеры записей, контрольные суммы заголовков и целостность
+// сжатого потока: gzip, xz и zstd сверяют свои суммы в конце потока.
+func (a tarArchive) Test(string) (ArchiveTestReport, error) {
+	var report ArchiveTestReport
+
+	stream, err := a.open(0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			return report, wrap("проверка архива", a.path, err)
+		}
+		report.Problems = append(report.Problems, fmt.Sprintf("не удалось открыть сжатый поток: %v", err))
+		return report, nil
+	}
+	defer stream.Close()
+
+	for {
+		header, err := stream.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("повреждён заголовок записи: %v", err))
+			return report, nil
+		}
+
+		result := EntryTestResult{Name: header.Name}
+		hash := crc32.NewIEEE()
+		n, err := io.Copy(hash, stream)
+		result.Size, result.CRC32 = uint64(n), hash.Sum32()
+		switch {
+		case err != nil:
+			result.Err = err
+		case n != header.Size:
+			result.Err = fmt.Errorf("размер %d вместо заявленных %d", n, header.Size)
+		}
+		report.Entries = append(report.Entries, result)
+		if err != nil {
+			return report, nil
+		}
+	}
+
+	// Дочитываем поток до конца, чтобы распаковщик сверил контрольную
+	// сумму сжатых данных.
+	if _, err := io.Copy(io.Discard, stream.decompressed); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("сжатый поток повреждён: %v", err))
+	}
+	return report, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type ratioReader struct {
+	r          io.Reader
+	compressed *countingReader
+	maxRatio   float64
+	read       int64
+}
+
+func (r *ratioReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read > ratioThreshold && float64(r.read) > r.maxRatio*float64(r.compressed.n) {
+		return n, fmt.Errorf("%w: степень сжатия больше %g", ErrLimitExceeded, r.maxRatio)
+	}
+	return n, err
+}
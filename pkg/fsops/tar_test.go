@@ -0,0 +1,192 @@
+package fsops
+
+import (
+	"archive/tar"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	files := map[string]string{
+		"docs/a.txt": "файл в папке",
+		"b.txt":      "корневой файл",
+		"empty/":     "",
+	}
+	want := map[string]string{
+		"data/":           "",
+		"data/b.txt":      "корневой файл",
+		"data/docs/":      "",
+		"data/docs/a.txt": "файл в папке",
+		"data/empty/":     "",
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		// fixture — готовый архив для форматов, которые можно только
+		// читать.
+		fixture string
+	}{
+		{"tar", FormatTar, ""},
+		{"tar.gz", FormatTarGz, ""},
+		{"tar.bz2", FormatTarBz2, "testdata/sample.tar.bz2"},
+		{"tar.xz", FormatTarXz, ""},
+		{"tar.zst", FormatTarZst, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := tt.fixture
+			if archivePath == "" {
+				src := filepath.Join(dir, "data")
+				writeTree(t, src, files)
+				archivePath = filepath.Join(dir, "a."+tt.name)
+				created, err := CreateArchive(archivePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := created.Add([]string{src}, AddOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a, err := OpenArchive(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Format() != tt.format {
+				t.Fatalf("Format = %v, want %v", a.Format(), tt.format)
+			}
+
+			listing, err := a.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, entry := range listing {
+				names = append(names, entry.Name)
+			}
+			var wantNames []string
+			for name := range want {
+				wantNames = append(wantNames, name)
+			}
+			slices.Sort(names)
+			slices.Sort(wantNames)
+			if !slices.Equal(names, wantNames) {
+				t.Errorf("List = %v, want %v", names, wantNames)
+			}
+
+			report, err := a.Test("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !report.OK() {
+				t.Errorf("Test: %q", report.Problems)
+			}
+
+			dest := filepath.Join(dir, "out")
+			if _, err := a.Extract(dest, ExtractOptions{Limits: DefaultExtractLimits}); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTree(t, dest); !maps.Equal(got, want) {
+				t.Errorf("Extract = %v, want %v", got, want)
+			}
+
+			one := filepath.Join(dir, "one.txt")
+			if err := a.ExtractEntry("data/docs/a.txt", one, ExtractOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(one); string(data) != want["data/docs/a.txt"] {
+				t.Errorf("ExtractEntry = %q", data)
+			}
+		})
+	}
+}
+
+func TestTarBz2ReadOnly(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "a.tar.bz2")
+	data, err := os.ReadFile("testdata/sample.tar.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archivePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := OpenArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Add([]string{archivePath}, AddOptions{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want %v", err, ErrUnsupported)
+	}
+}
+
+func TestTarExtractUnsafePaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"выход через ..", []*tar.Header{{Name: "../evil.txt", Typeflag: tar.TypeReg, Size: 1}}},
+		{".. в середине пути", []*tar.Header{{Name: "a/../../evil.txt", Typeflag: tar.TypeReg, Size: 1}}},
+		{"абсолютный путь", []*tar.Header{{Name: "/tmp/evil.txt", Typeflag: tar.TypeReg, Size: 1}}},
+		{"ссылка наружу", []*tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../outside"}}},
+		{"запись через ссылку", []*tar.Header{
+			{Name: "dir", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "up", Typeflag: tar.TypeSymlink, Linkname: "dir/.."},
+			{Name: "up/evil.txt", Typeflag: tar.TypeReg, Size: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			archivePath := filepath.Join(root, "a.tar")
+			writeTar(t, archivePath, tt.headers)
+
+			a, err := OpenArchive(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(root, "dest")
+			if _, err := a.Extract(dest, ExtractOptions{}); !errors.Is(err, ErrUnsafePath) {
+				t.Fatalf("err = %v, want %v", err, ErrUnsafePath)
+			}
+			if _, err := os.Stat(filepath.Join(root, "evil.txt")); err == nil {
+				t.Error("файл записан за пределами папки назначения")
+			}
+		})
+	}
+}
+
+// writeTar записывает несжатый tar из заголовков; у обычных файлов
+// содержимое — Size байт "x".
+func writeTar(t *testing.T, archivePath string, headers []*tar.Header) {
+	t.Helper()
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	w := tar.NewWriter(file)
+	for _, header := range headers {
+		header.Mode = 0o644
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		for range header.Size {
+			if _, err := w.Write([]byte("x")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
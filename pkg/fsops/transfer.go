@@ -0,0 +1,333 @@
+package fsops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ConflictAction int
+
+const (
+	ConflictFail ConflictAction = iota
+	ConflictOverwrite
+	ConflictSkip
+	ConflictRename
+)
+
+type TransferOptions struct {
+	// OnConflict вызывается, если dst уже существует. Без него
+	// операция завершается ошибкой ErrAlreadyExists. При замене прежний
+	// dst удаляется только после того, как на его место готова копия.
+	OnConflict func(src, dst string) ConflictAction
+	// Ctx прерывает операцию; недописанные файлы и папки при этом
+	// удаляются, а при перемещении источник остаётся на месте.
+	// Progress, если задан, получает сведения о ходе операции.
+	Ctx      context.Context
+	Progress func(ProgressInfo)
+	// Limits ограничивают CopyFromFS так же, как распаковку архива;
+	// Copy, Move и Rename их не учитывают.
+	Limits ExtractLimits
+}
+
+// Copy копирует файл, символическую ссылку или дерево папок src в dst,
+// сохраняя права доступа и время изменения. Если обе стороны — папки,
+// их содержимое объединяется, а конфликты решаются для каждого файла.
+func Copy(src, dst string, opts TransferOptions) error {
+	t := &transferer{op: "копирование", opts: opts}
+	return t.start(src, dst)
+}
+
+// Move перемещает src в dst. Если переименование невозможно из-за разных
+// файловых систем, src копируется и затем удаляется.
+func Move(src, dst string, opts TransferOptions) error {
+	t := &transferer{op: "перемещение", opts: opts, move: true}
+	return t.start(src, dst)
+}
+
+// Rename переименовывает path в newName в той же папке. В отличие от Move,
+// существующая папка с именем newName считается конфликтом, а не объединяется.
+func Rename(path, newName string, opts TransferOptions) error {
+	if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+		return wrap("переименование", path, fmt.Errorf("%w: %q", ErrInvalidName, newName))
+	}
+
+	t := &transferer{op: "переименование", opts: opts, move: true, noMerge: true}
+	return t.start(path, filepath.Join(filepath.Dir(path), newName))
+}
+
+// IntoDir возвращает dst/имя_src, если dst — существующая папка, иначе dst.
+// Так копирование и перемещение в папку ведут себя как cp и mv.
+func IntoDir(src, dst string) string {
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		return filepath.Join(dst, filepath.Base(src))
+	}
+	return dst
+}
+
+type transferer struct {
+	op      string
+	opts    TransferOptions
+	move    bool
+	noMerge bool
+	tracker *tracker
+}
+
+func (t *transferer) start(src, dst string) error {
+	src, dst = filepath.Clean(src), filepath.Clean(dst)
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return wrap(t.op, src, err)
+	}
+
+	if src == dst && t.move {
+		return nil
+	}
+	if src != dst && info.IsDir() && isWithin(src, dst) {
+		return wrap(t.op, dst, ErrIntoItself)
+	}
+
+	var bytes int64
+	var files int
+	if t.opts.Progress != nil {
+		bytes, files = measureTree(src, info)
+	}
+	t.tracker = newTracker(t.opts.Ctx, t.opts.Progress, bytes, files)
+	defer t.tracker.finish()
+	return t.run(src, dst, info)
+}
+
+func (t *transferer) run(src, dst string, info fs.FileInfo) error {
+	if err := t.tracker.err(); err != nil {
+		return wrap(t.op, src, err)
+	}
+
+	replace := false
+	dstInfo, err := os.Lstat(dst)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return wrap(t.op, dst, err)
+	case info.IsDir() && dstInfo.IsDir() && !t.noMerge && src != dst:
+		return t.merge(src, dst)
+	default:
+		action := ConflictFail
+		if t.opts.OnConflict != nil {
+			action = t.opts.OnConflict(src, dst)
+		}
+
+		switch action {
+		case ConflictSkip:
+			if t.tracker.enabled() {
+				t.tracker.skip(measureTree(src, info))
+			}
+			return nil
+		case ConflictRename:
+			dst = freeName(dst)
+		case ConflictOverwrite:
+			if src == dst {
+				return wrap(t.op, dst, ErrSameFile)
+			}
+			if isWithin(dst, src) {
+				return wrap(t.op, dst, ErrContainsSource)
+			}
+			replace = true
+		default:
+			return wrap(t.op, dst, ErrAlreadyExists)
+		}
+	}
+
+	// Заменяемый dst остаётся на месте, пока src не перенесён или не
+	// скопирован целиком.
+	if t.move {
+		rename := os.Rename
+		if replace {
+			rename = replacePath
+		}
+		err := rename(src, dst)
+		if err == nil && t.tracker.enabled() {
+			t.tracker.skip(measureTree(dst, info))
+		}
+		if err == nil || !isCrossDevice(err) {
+			return wrap(t.op, src, err)
+		}
+	}
+
+	if replace {
+		err = replaceWith(dst, func(tmp string) error {
+			return copyTree(src, tmp, info, t.tracker)
+		})
+	} else if err = copyTree(src, dst, info, t.tracker); err != nil && isCanceled(err) {
+		// copyTree создаёт dst с нуля, поэтому недоделанную копию
+		// можно удалить целиком.
+		os.RemoveAll(dst)
+	}
+	if err != nil {
+		return wrap(t.op, src, err)
+	}
+	if t.move {
+		return wrap(t.op, src, os.RemoveAll(src))
+	}
+	return nil
+}
+
+func (t *transferer) merge(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return wrap(t.op, src, err)
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return wrap(t.op, filepath.Join(src, entry.Name()), err)
+		}
+		if err := t.run(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), info); err != nil {
+			return err
+		}
+	}
+
+	if t.move {
+		// Если часть файлов была пропущена, исходная папка остаётся.
+		if err := os.Remove(src); err != nil && !isNotEmpty(src) {
+			return wrap(t.op, src, err)
+		}
+	}
+	return nil
+}
+
+func copyTree(src, dst string, info fs.FileInfo, tr *tracker) error {
+	if err := tr.err(); err != nil {
+		return err
+	}
+
+	switch mode := info.Mode(); {
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, dst); err != nil {
+			return err
+		}
+		tr.fileDone()
+		return nil
+	case mode.IsDir():
+		if err := os.Mkdir(dst, 0700); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			childInfo, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), childInfo, tr); err != nil {
+				return err
+			}
+		}
+		return preserveAttrs(dst, info)
+	case mode.IsRegular():
+		if err := copyFile(src, dst, info, tr); err != nil {
+			return err
+		}
+		tr.fileDone()
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", src, ErrUnsupported)
+	}
+}
+
+func copyFile(src, dst string, info fs.FileInfo, tr *tracker) error {
+	tr.begin(src)
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, tr.reader(in)); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return preserveAttrs(dst, info)
+}
+
+func preserveAttrs(path string, info fs.FileInfo) error {
+	if err := os.Chmod(path, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// measureTree считает размер и число файлов дерева для индикатора
+// прогресса. Недоступные части дерева просто не учитываются.
+func measureTree(path string, info fs.FileInfo) (int64, int) {
+	if !info.IsDir() {
+		if info.Mode().IsRegular() {
+			return info.Size(), 1
+		}
+		return 0, 1
+	}
+
+	var bytes int64
+	var files int
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		files++
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				bytes += info.Size()
+			}
+		}
+		return nil
+	})
+	return bytes, files
+}
+
+// freeName подбирает свободное имя вида "имя (N).расширение".
+func freeName(path string) string {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if _, err := os.Lstat(candidate); errors.Is(err, fs.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func isNotEmpty(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err == nil && len(entries) > 0
+}
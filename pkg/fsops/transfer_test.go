@@ -0,0 +1,211 @@
+package fsops
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree создаёт в root файлы name -> содержимое; имена с "/" в конце
+// — папки.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree возвращает содержимое root в том же виде, что принимает
+// writeTree; промежуточные папки тоже попадают в результат.
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if entry.IsDir() {
+			files[name+"/"] = ""
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		files[name] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       string
+		files    map[string]string
+		src, dst string
+		action   ConflictAction
+		cancel   bool
+		want     map[string]string
+		err      error
+	}{
+		{
+			name: "копирование", op: "copy",
+			files: map[string]string{"a.txt": "a"},
+			src:   "a.txt", dst: "b.txt",
+			want: map[string]string{"a.txt": "a", "b.txt": "a"},
+		},
+		{
+			name: "конфликт без решения", op: "copy",
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			src:   "a.txt", dst: "b.txt", action: ConflictFail,
+			want: map[string]string{"a.txt": "a", "b.txt": "b"},
+			err:  ErrAlreadyExists,
+		},
+		{
+			name: "замена файла", op: "copy",
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			src:   "a.txt", dst: "b.txt", action: ConflictOverwrite,
+			want: map[string]string{"a.txt": "a", "b.txt": "a"},
+		},
+		{
+			name: "пропуск", op: "copy",
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			src:   "a.txt", dst: "b.txt", action: ConflictSkip,
+			want: map[string]string{"a.txt": "a", "b.txt": "b"},
+		},
+		{
+			name: "новое имя", op: "copy",
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			src:   "a.txt", dst: "b.txt", action: ConflictRename,
+			want: map[string]string{"a.txt": "a", "b.txt": "b", "b (1).txt": "a"},
+		},
+		{
+			name: "объединение папок", op: "copy",
+			files: map[string]string{"src/a": "1", "src/sub/c": "3", "dst/b": "2"},
+			src:   "src", dst: "dst",
+			want: map[string]string{
+				"src/": "", "src/a": "1", "src/sub/": "", "src/sub/c": "3",
+				"dst/": "", "dst/a": "1", "dst/b": "2", "dst/sub/": "", "dst/sub/c": "3",
+			},
+		},
+		{
+			name: "замена папки файлом", op: "copy",
+			files: map[string]string{"a.txt": "a", "d/x": "x"},
+			src:   "a.txt", dst: "d", action: ConflictOverwrite,
+			want: map[string]string{"a.txt": "a", "d": "a"},
+		},
+		{
+			name: "прерванная замена", op: "copy",
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			src:   "a.txt", dst: "b.txt", action: ConflictOverwrite, cancel: true,
+			want: map[string]string{"a.txt": "a", "b.txt": "b"},
+			err:  context.Canceled,
+		},
+		{
+			name: "замена папки с источником", op: "copy",
+			files: map[string]string{"d/f": "f"},
+			src:   "d/f", dst: "d", action: ConflictOverwrite,
+			want: map[string]string{"d/": "", "d/f": "f"},
+			err:  ErrContainsSource,
+		},
+		{
+			name: "перемещение в другую папку", op: "move",
+			files: map[string]string{"x/f": "f", "y/": ""},
+			src:   "x/f", dst: "y/f",
+			want: map[string]string{"x/": "", "y/": "", "y/f": "f"},
+		},
+		{
+			name: "перемещение с заменой", op: "move",
+			files: map[string]string{"x/f": "new", "y/f": "old"},
+			src:   "x/f", dst: "y/f", action: ConflictOverwrite,
+			want: map[string]string{"x/": "", "y/": "", "y/f": "new"},
+		},
+		{
+			name: "перемещение папки в саму себя", op: "move",
+			files: map[string]string{"d/f": "f"},
+			src:   "d", dst: "d/sub",
+			want: map[string]string{"d/": "", "d/f": "f"},
+			err:  ErrIntoItself,
+		},
+		{
+			name: "перемещение на место своей папки", op: "move",
+			files: map[string]string{"d/f": "f"},
+			src:   "d/f", dst: "d", action: ConflictOverwrite,
+			want: map[string]string{"d/": "", "d/f": "f"},
+			err:  ErrContainsSource,
+		},
+		{
+			name: "переименование на место папки", op: "rename",
+			files: map[string]string{"a/x": "x", "b/y": "y"},
+			src:   "a", dst: "b", action: ConflictOverwrite,
+			want: map[string]string{"b/": "", "b/x": "x"},
+		},
+		{
+			name: "переименование в занятое имя", op: "rename",
+			files: map[string]string{"a/x": "x", "b/y": "y"},
+			src:   "a", dst: "b", action: ConflictFail,
+			want: map[string]string{"a/": "", "a/x": "x", "b/": "", "b/y": "y"},
+			err:  ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tt.files)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			opts := TransferOptions{
+				Ctx: ctx,
+				OnConflict: func(src, dst string) ConflictAction {
+					if tt.cancel {
+						cancel()
+					}
+					return tt.action
+				},
+			}
+
+			src, dst := filepath.Join(root, tt.src), filepath.Join(root, tt.dst)
+			var err error
+			switch tt.op {
+			case "copy":
+				err = Copy(src, dst, opts)
+			case "move":
+				err = Move(src, dst, opts)
+			case "rename":
+				err = Rename(src, filepath.Base(dst), opts)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			if got := readTree(t, root); !maps.Equal(got, tt.want) {
+				t.Errorf("tree = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package fsops
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"unicode"
+)
+
+// WriteXML записывает пары тег-значение как дочерние элементы <root>
+// в порядке сортировки тегов.
+func WriteXML(path string, data map[string]string) error {
+	tags := make([]string, 0, len(data))
+	for tag := range data {
+		if !validTag(tag) {
+			return wrap("запись XML в файл", path, fmt.Errorf("%w: <%s>", ErrInvalidName, tag))
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n")
+	for _, tag := range tags {
+		fmt.Fprintf(&buf, "  <%s>", tag)
+		xml.EscapeText(&buf, []byte(data[tag]))
+		fmt.Fprintf(&buf, "</%s>\n", tag)
+	}
+	buf.WriteString("</root>")
+
+	return wrap("запись XML в файл", path, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func validTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for i, r := range tag {
+		switch {
+		case unicode.IsLetter(r), r == '_', r == ':':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package fsops
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+func CreateZip(archivePath string) error {
+	zipFile, err := os.Create(archivePath)
+	if err != nil {
+		return wrap("создание архива", archivePath, err)
+	}
+	defer zipFile.Close()
+
+	if err := zip.NewWriter(zipFile).Close(); err != nil {
+		return wrap("создание архива", archivePath, err)
+	}
+	return wrap("создание архива", archivePath, zipFile.Close())
+}
+
+// rewriteZip собирает новую версию архива во временном файле и атомарно
+// подменяет ею исходный. Комментарий архива сохраняется.
+func rewriteZip(archivePath string, fn func(src *zip.Reader, dst *zip.Writer) error) error {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	err = WriteAtomic(archivePath, func(w io.Writer) error {
+		zipWriter := zip.NewWriter(w)
+		if err := zipWriter.SetComment(zipReader.Comment); err != nil {
+			return err
+		}
+		if err := fn(&zipReader.Reader, zipWriter); err != nil {
+			return err
+		}
+		if err := zipWriter.Close(); err != nil {
+			return err
+		}
+		// Архив закрывается до замены: в Windows открытый файл нельзя
+		// переименовать поверх.
+		return zipReader.Close()
+	})
+	return wrap("запись архива", archivePath, err)
+}
+
+func ZipEntries(archivePath string) ([]string, error) {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return nil, wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	names := make([]string, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		names = append(names, file.Name)
+	}
+	return names, nil
+}
+
+type ArchiveEntry struct {
+	Name           string
+	Size           uint64
+	CompressedSize uint64
+	Method         string
+	CRC32          uint32
+	Modified       time.Time
+	Mode           fs.FileMode
+	Comment        string
+	// Encryption — "aes-256", "zipcrypto" или пустая строка.
+	Encryption string
+}
+
+// Ratio возвращает долю места, сэкономленного сжатием: 0 для несжатых
+// записей, 0.75 — если запись занимает четверть исходного размера.
+func (e ArchiveEntry) Ratio() float64 {
+	if e.Size == 0 {
+		return 0
+	}
+	return 1 - float64(e.CompressedSize)/float64(e.Size)
+}
+
+// ZipListing возвращает подробные сведения о записях архива в порядке
+// центрального каталога.
+func ZipListing(archivePath string) ([]ArchiveEntry, error) {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return nil, wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	entries := make([]ArchiveEntry, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		entries = append(entries, zipArchiveEntry(file))
+	}
+	return entries, nil
+}
+
+func zipArchiveEntry(file *zip.File) ArchiveEntry {
+	return ArchiveEntry{
+		Name:           file.Name,
+		Size:           file.UncompressedSize64,
+		CompressedSize: file.CompressedSize64,
+		Method:         methodLabel(file),
+		CRC32:          file.CRC32,
+		Modified:       file.Modified,
+		Mode:           file.Mode(),
+		Comment:        file.Comment,
+		Encryption:     zipEncryption(file),
+	}
+}
+
+func MethodName(method uint16) string {
+	switch method {
+	case zip.Store:
+		return "store"
+	case zip.Deflate:
+		return "deflate"
+	case ZipZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("method-%d", method)
+	}
+}
@@ -0,0 +1,53 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// zipEntry — запись тестового архива: data — содержимое файла или цель
+// ссылки, имя с "/" в конце — папка.
+type zipEntry struct {
+	name string
+	data []byte
+	mode fs.FileMode
+}
+
+// buildZip собирает архив из entries в заданном порядке с методом
+// сжатия method.
+func buildZip(t *testing.T, method uint16, entries ...zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: method}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeZip записывает архив из buildZip в файл во временной папке.
+func writeZip(t *testing.T, method uint16, entries ...zipEntry) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "a.zip")
+	if err := os.WriteFile(archivePath, buildZip(t, method, entries...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
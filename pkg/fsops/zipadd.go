@@ -0,0 +1,270 @@
+package fsops
+
+import (
+	"archive/zip"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type SymlinkMode int
+
+const (
+	// SymlinkStore сохраняет в архиве саму ссылку, как это делает zip -y.
+	SymlinkStore SymlinkMode = iota
+	SymlinkFollow
+	SymlinkSkip
+)
+
+type AddOptions struct {
+	// Include и Exclude — шаблоны вида *.go, docs/** или build/*.
+	// Шаблон без "/" сравнивается с именем файла, со "/" — с путём
+	// внутри архива. "**" совпадает с любым числом папок.
+	// Пустой Include означает «все файлы».
+	Include  []string
+	Exclude  []string
+	Symlinks SymlinkMode
+	// Dir — папка внутри архива, в которую добавляются paths;
+	// пустая строка означает корень архива.
+	Dir string
+	// Password, если задан, шифрует новые записи zip по стандарту
+	// WinZip AES-256. Прежние записи архива остаются как были.
+	Password string
+	// Compression — сжатие новых записей, nil означает
+	// DefaultCompression. Rules переопределяют его для отдельных файлов,
+	// а уже сжатые форматы (jpg, png, zip, gz, mp4 и другие) без
+	// подходящего правила сохраняются как store. Для tar используется
+	// только уровень, метод задаёт формат архива.
+	Compression *Compression
+	Rules       []CompressionRule
+	// Workers — сколько файлов сжимается одновременно; 0 означает по
+	// числу процессоров, 1 — последовательно.
+	Workers int
+	// Ctx прерывает добавление; архив при этом остаётся прежним.
+	// Progress, если задан, получает сведения о ходе операции.
+	Ctx      context.Context
+	Progress func(ProgressInfo)
+}
+
+type AddSummary struct {
+	Added    []string
+	Files    int
+	Dirs     int
+	Symlinks int
+	Skipped  int
+	Bytes    int64
+}
+
+type addSource struct {
+	name string
+	path string
+	info fs.FileInfo
+}
+
+// AddToZip добавляет в архив файлы и папки paths. Папки добавляются
+// рекурсивно, пути внутри архива строятся относительно папки, в которой
+// лежит каждый из paths. Архив переписывается целиком: прежние записи
+// копируются без перепаковки, записи с теми же именами заменяются.
+func AddToZip(archivePath string, paths []string, opts AddOptions) (AddSummary, error) {
+	sources, replaced, summary, err := planAdd(archivePath, paths, opts)
+	if err != nil {
+		return summary, err
+	}
+
+	tr := summary.tracker(opts)
+	defer tr.finish()
+
+	err = rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		for _, file := range src.File {
+			if err := tr.err(); err != nil {
+				return err
+			}
+			if replaced[file.Name] {
+				continue
+			}
+			if err := dst.Copy(file); err != nil {
+				return err
+			}
+		}
+
+		return writeZipEntries(dst, sources, opts, tr)
+	})
+	return summary, err
+}
+
+// planAdd обходит paths и возвращает будущие записи архива вместе с
+// множеством их имён, чтобы заменить одноимённые записи.
+func planAdd(archivePath string, paths []string, opts AddOptions) ([]addSource, map[string]bool, AddSummary, error) {
+	var summary AddSummary
+
+	archiveAbs, err := filepath.Abs(archivePath)
+	if err != nil {
+		return nil, nil, summary, err
+	}
+
+	dir := strings.Trim(path.Clean("/"+opts.Dir), "/")
+	if dir != "" {
+		if err := validEntryName(dir); err != nil {
+			return nil, nil, summary, wrap("добавление в архив", archivePath, err)
+		}
+	}
+
+	var sources []addSource
+	for _, p := range paths {
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, nil, summary, wrap("добавление в архив", p, err)
+		}
+
+		w := &addWalker{opts: opts, archive: archiveAbs, summary: &summary, visited: map[string]bool{}}
+		if err := w.walk(p, path.Join(dir, filepath.Base(filepath.Clean(p))), info); err != nil {
+			return nil, nil, summary, wrap("добавление в архив", p, err)
+		}
+		sources = append(sources, w.sources...)
+	}
+
+	replaced := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		replaced[src.name] = true
+	}
+	return sources, replaced, summary, nil
+}
+
+type addWalker struct {
+	opts    AddOptions
+	archive string
+	summary *AddSummary
+	sources []addSource
+	visited map[string]bool
+}
+
+func (w *addWalker) walk(filePath, name string, info fs.FileInfo) error {
+	if abs, err := filepath.Abs(filePath); err == nil && abs == w.archive {
+		return nil
+	}
+	if matchAny(w.opts.Exclude, name) {
+		w.summary.Skipped++
+		return nil
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		switch w.opts.Symlinks {
+		case SymlinkSkip:
+			w.summary.Skipped++
+			return nil
+		case SymlinkFollow:
+			target, err := os.Stat(filePath)
+			if err != nil {
+				return err
+			}
+			info = target
+		}
+	}
+
+	if !info.IsDir() {
+		if len(w.opts.Include) > 0 && !matchAny(w.opts.Include, name) {
+			w.summary.Skipped++
+			return nil
+		}
+		if !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+			w.summary.Skipped++
+			return nil
+		}
+
+		w.add(addSource{name: name, path: filePath, info: info})
+		return nil
+	}
+
+	// Защита от циклов при переходе по символическим ссылкам на папки.
+	realPath, err := filepath.EvalSymlinks(filePath)
+	if err != nil {
+		return err
+	}
+	if w.visited[realPath] {
+		w.summary.Skipped++
+		return nil
+	}
+	w.visited[realPath] = true
+	defer delete(w.visited, realPath)
+
+	if len(w.opts.Include) == 0 {
+		w.add(addSource{name: name + "/", path: filePath, info: info})
+	}
+
+	entries, err := os.ReadDir(filePath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		childInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if err := w.walk(filepath.Join(filePath, entry.Name()), name+"/"+entry.Name(), childInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *addWalker) add(source addSource) {
+	w.sources = append(w.sources, source)
+	w.summary.Added = append(w.summary.Added, source.name)
+
+	switch mode := source.info.Mode(); {
+	case mode.IsDir():
+		w.summary.Dirs++
+	case mode&fs.ModeSymlink != 0:
+		w.summary.Symlinks++
+	default:
+		w.summary.Files++
+		w.summary.Bytes += source.info.Size()
+	}
+}
+
+// tracker считает прогресс по файлам и ссылкам из плана добавления.
+func (s AddSummary) tracker(opts AddOptions) *tracker {
+	return newTracker(opts.Ctx, opts.Progress, s.Bytes, s.Files+s.Symlinks)
+}
+
+func matchAny(patterns []string, name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok {
+				return true
+			}
+			continue
+		}
+		if matchGlob(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob сравнивает путь с шаблоном по сегментам; "**" поглощает
+// любое количество сегментов, в том числе ноль.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,220 @@
+package fsops
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZipZstd — номер метода Zstandard в zip по спецификации APPNOTE 6.3.8,
+// его же используют WinZip и 7-Zip.
+const ZipZstd = zstd.ZipMethodWinZip
+
+// zstdDecompressor общий для всех архивов: он держит пул декодеров.
+var zstdDecompressor = zstd.ZipDecompressor()
+
+// openZipReader открывает архив так же, как zip.OpenReader, и учит его
+// читать записи zstd.
+func openZipReader(archivePath string) (*zip.ReadCloser, error) {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	registerZstd(&zipReader.Reader)
+	return zipReader, nil
+}
+
+// registerZstd регистрирует распаковщик zstd только для r, а не для
+// archive/zip целиком, чтобы импорт пакета не менял чужие читатели.
+func registerZstd(r *zip.Reader) {
+	r.RegisterDecompressor(ZipZstd, zstdDecompressor)
+}
+
+// Compression задаёт метод и уровень сжатия записи.
+type Compression struct {
+	// Method — zip.Store, zip.Deflate или ZipZstd.
+	Method uint16
+	// Level — уровень от 0 (быстрее) до 9 (сильнее), -1 — уровень
+	// метода по умолчанию. Для store не используется.
+	Level int
+}
+
+var DefaultCompression = Compression{Method: zip.Deflate, Level: -1}
+
+// ParseCompression разбирает запись вида "store", "deflate", "deflate:9"
+// или "zstd:3".
+func ParseCompression(s string) (Compression, error) {
+	name, levelText, hasLevel := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+
+	c := Compression{Level: -1}
+	switch name {
+	case "store":
+		c.Method = zip.Store
+	case "deflate":
+		c.Method = zip.Deflate
+	case "zstd":
+		c.Method = ZipZstd
+	default:
+		return c, fmt.Errorf("неизвестный метод сжатия %q, допустимы: store, deflate, zstd", name)
+	}
+
+	if hasLevel {
+		level, err := strconv.Atoi(levelText)
+		if err != nil || level < 0 || level > 9 {
+			return c, fmt.Errorf("уровень сжатия должен быть от 0 до 9, а не %q", levelText)
+		}
+		c.Level = level
+	}
+	return c, nil
+}
+
+func (c Compression) String() string {
+	name := MethodName(c.Method)
+	if c.Level < 0 || c.Method == zip.Store {
+		return name
+	}
+	return name + ":" + strconv.Itoa(c.Level)
+}
+
+// CompressionRule задаёт сжатие для записей, подходящих под шаблон.
+// Шаблоны устроены так же, как AddOptions.Include.
+type CompressionRule struct {
+	Pattern     string
+	Compression Compression
+}
+
+// ParseCompressionRule разбирает правило вида "*.log=zstd:9".
+func ParseCompressionRule(s string) (CompressionRule, error) {
+	pattern, method, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" {
+		return CompressionRule{}, fmt.Errorf("правило %q должно иметь вид ШАБЛОН=МЕТОД[:УРОВЕНЬ]", s)
+	}
+	c, err := ParseCompression(method)
+	return CompressionRule{Pattern: pattern, Compression: c}, err
+}
+
+// precompressedExts — форматы, которые уже сжаты: повторное сжатие
+// только тратит время.
+var precompressedExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".heic": true,
+	".mp3": true, ".mp4": true, ".m4a": true, ".mkv": true, ".mov": true, ".avi": true, ".webm": true, ".ogg": true,
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true, ".7z": true, ".rar": true,
+	".jar": true, ".apk": true, ".docx": true, ".xlsx": true, ".pptx": true, ".odt": true, ".pdf": true,
+}
+
+// compressionFor выбирает сжатие записи: первое подходящее правило,
+// затем store для уже сжатых форматов, затем сжатие всего архива.
+func (o AddOptions) compressionFor(name string) Compression {
+	for _, rule := range o.Rules {
+		if matchAny([]string{rule.Pattern}, name) {
+			return rule.Compression
+		}
+	}
+	if precompressedExts[strings.ToLower(path.Ext(name))] {
+		return Compression{Method: zip.Store, Level: -1}
+	}
+	if o.Compression != nil {
+		return *o.Compression
+	}
+	return DefaultCompression
+}
+
+func newCompressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c.Method {
+	case zip.Store:
+		return nopWriteCloser{w}, nil
+	case zip.Deflate:
+		return flate.NewWriter(w, deflateLevel(c.Level))
+	case ZipZstd:
+		// Параллельность обеспечивает пул writeZipEntries, поэтому
+		// кодировщику хватает одного потока.
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel(c.Level)), zstd.WithEncoderConcurrency(1))
+	default:
+		return nil, fmt.Errorf("%w: метод сжатия %s", ErrUnsupported, MethodName(c.Method))
+	}
+}
+
+func newDecompressor(r io.Reader, method uint16) (io.ReadCloser, error) {
+	switch method {
+	case zip.Store:
+		return io.NopCloser(r), nil
+	case zip.Deflate:
+		return flate.NewReader(r), nil
+	case ZipZstd:
+		return zstdDecompressor(r), nil
+	default:
+		return nil, fmt.Errorf("%w: метод сжатия %s", ErrUnsupported, MethodName(method))
+	}
+}
+
+func deflateLevel(level int) int {
+	if level < 0 {
+		return flate.DefaultCompression
+	}
+	return level
+}
+
+func zstdLevel(level int) zstd.EncoderLevel {
+	switch {
+	case level < 0:
+		return zstd.SpeedDefault
+	case level <= 1:
+		return zstd.SpeedFastest
+	case level <= 5:
+		return zstd.SpeedDefault
+	case level <= 7:
+		return zstd.SpeedBetterCompression
+	default:
+		return zstd.SpeedBestCompression
+	}
+}
+
+// Биты 1–2 общего флага записи описывают степень сжатия deflate.
+const (
+	flagDeflateMax       = 0x2
+	flagDeflateFast      = 0x4
+	flagDeflateSuperFast = 0x6
+	flagDeflateMask      = 0x6
+)
+
+// deflateFlags возвращает биты степени сжатия для уровня по тем же
+// правилам, что и у zip -0…-9: 8–9 — максимальное, 2 — быстрое,
+// 0–1 — самое быстрое.
+func deflateFlags(c Compression) uint16 {
+	if c.Method != zip.Deflate || c.Level < 0 {
+		return 0
+	}
+	switch {
+	case c.Level >= 8:
+		return flagDeflateMax
+	case c.Level == 2:
+		return flagDeflateFast
+	case c.Level <= 1:
+		return flagDeflateSuperFast
+	}
+	return 0
+}
+
+// methodLabel описывает сжатие записи для листинга.
+func methodLabel(file *zip.File) string {
+	method := zipMethod(file)
+	name := MethodName(method)
+	if method != zip.Deflate {
+		return name
+	}
+	switch file.Flags & flagDeflateMask {
+	case flagDeflateMax:
+		return name + "-max"
+	case flagDeflateFast:
+		return name + "-fast"
+	case flagDeflateSuperFast:
+		return name + "-superfast"
+	}
+	return name
+}
@@ -0,0 +1,75 @@
+package fsops
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddToZipCompression(t *testing.T) {
+	data := strings.Repeat("сжимаемый текст ", 1000)
+
+	tests := []struct {
+		name          string
+		compression   string
+		password      string
+		method        uint16
+		readerVersion uint16
+	}{
+		{"store", "store", "", zip.Store, 20},
+		{"deflate", "deflate:9", "", zip.Deflate, 20},
+		{"zstd", "zstd:3", "", ZipZstd, 63},
+		{"deflate с шифрованием", "deflate", "пароль", methodAES, 51},
+		{"zstd с шифрованием", "zstd", "пароль", methodAES, 63},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCompression(tt.compression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			src := filepath.Join(dir, "file.txt")
+			if err := os.WriteFile(src, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			archivePath := filepath.Join(dir, "a.zip")
+			if err := CreateZip(archivePath); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := AddToZip(archivePath, []string{src}, AddOptions{Compression: &c, Password: tt.password}); err != nil {
+				t.Fatal(err)
+			}
+
+			zfs, err := OpenZipFS(archivePath, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer zfs.Close()
+			file := zfs.files["file.txt"]
+			if file.Method != tt.method {
+				t.Errorf("Method = %d, want %d", file.Method, tt.method)
+			}
+			if file.ReaderVersion != tt.readerVersion {
+				t.Errorf("ReaderVersion = %d, want %d", file.ReaderVersion, tt.readerVersion)
+			}
+
+			f, err := zfs.Open("file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := io.ReadAll(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != data {
+				t.Error("содержимое после распаковки отличается")
+			}
+		})
+	}
+}
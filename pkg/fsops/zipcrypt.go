@@ -0,0 +1,375 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Шифрование WinZip AES (AE-1 и AE-2) и чтение старого ZipCrypto.
+// archive/zip не умеет ни того, ни другого, поэтому зашифрованные записи
+// читаются через OpenRaw и пишутся через CreateRaw.
+const (
+	methodAES      = 99
+	extraAES       = 0x9901
+	extraTimestamp = 0x5455
+
+	flagEncrypted      = 0x1
+	flagDataDescriptor = 0x8
+	flagUTF8           = 0x800
+
+	aes256Strength = 3
+	aes256KeyLen   = 32
+	aes256SaltLen  = 16
+	aesVerifierLen = 2
+	aesMACLen      = 10
+	aesIterations  = 1000
+
+	zipCryptoHeaderLen = 12
+)
+
+// aesExtra — содержимое дополнительного поля 0x9901.
+type aesExtra struct {
+	version  uint16
+	strength byte
+	method   uint16
+}
+
+func parseAESExtra(extra []byte) (aesExtra, bool) {
+	data, ok := findExtra(extra, extraAES)
+	if !ok || len(data) < 7 || string(data[2:4]) != "AE" {
+		return aesExtra{}, false
+	}
+	return aesExtra{
+		version:  binary.LittleEndian.Uint16(data),
+		strength: data[4],
+		method:   binary.LittleEndian.Uint16(data[5:]),
+	}, true
+}
+
+func aesKeyLen(strength byte) int {
+	switch strength {
+	case 1:
+		return 16
+	case 2:
+		return 24
+	case 3:
+		return 32
+	}
+	return 0
+}
+
+// zipEncryption возвращает название шифрования записи или пустую строку.
+func zipEncryption(file *zip.File) string {
+	if file.Flags&flagEncrypted == 0 {
+		return ""
+	}
+	if file.Method != methodAES {
+		return "zipcrypto"
+	}
+	if extra, ok := parseAESExtra(file.Extra); ok && aesKeyLen(extra.strength) > 0 {
+		return fmt.Sprintf("aes-%d", aesKeyLen(extra.strength)*8)
+	}
+	return "aes"
+}
+
+// zipMethod возвращает настоящий метод сжатия: у записей AES он хранится
+// в дополнительном поле, а в заголовке стоит 99.
+func zipMethod(file *zip.File) uint16 {
+	if file.Method == methodAES {
+		if extra, ok := parseAESExtra(file.Extra); ok {
+			return extra.method
+		}
+	}
+	return file.Method
+}
+
+// openZipFile открывает запись на чтение, при необходимости расшифровывая
+// её паролем.
+func openZipFile(file *zip.File, password string) (io.ReadCloser, error) {
+	if file.Flags&flagEncrypted == 0 {
+		return file.Open()
+	}
+	if password == "" {
+		return nil, ErrNeedPassword
+	}
+
+	raw, err := file.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypted io.Reader
+	checkCRC := true
+	if file.Method == methodAES {
+		extra, ok := parseAESExtra(file.Extra)
+		if !ok || aesKeyLen(extra.strength) == 0 {
+			return nil, fmt.Errorf("%w: повреждено поле шифрования AES", zip.ErrFormat)
+		}
+		decrypted, err = newAESReader(raw, file.CompressedSize64, password, aesKeyLen(extra.strength))
+		// В AE-2 CRC не хранится, целостность проверяет код аутентификации.
+		checkCRC = extra.version != 2
+	} else {
+		decrypted, err = newZipCryptoReader(raw, file, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := newDecompressor(decrypted, zipMethod(file))
+	if err != nil {
+		return nil, err
+	}
+	return &checksumReader{rc: data, hash: crc32.NewIEEE(), size: file.UncompressedSize64, crc: file.CRC32, checkCRC: checkCRC}, nil
+}
+
+// checksumReader сверяет размер и CRC-32 распакованных данных в конце
+// записи, как это делает archive/zip для обычных записей.
+type checksumReader struct {
+	rc       io.ReadCloser
+	hash     hash.Hash32
+	read     uint64
+	size     uint64
+	crc      uint32
+	checkCRC bool
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.hash.Write(p[:n])
+	r.read += uint64(n)
+	if r.read > r.size {
+		return n, zip.ErrFormat
+	}
+	if errors.Is(err, io.EOF) {
+		if r.read != r.size {
+			return n, io.ErrUnexpectedEOF
+		}
+		if r.checkCRC && r.hash.Sum32() != r.crc {
+			return n, zip.ErrChecksum
+		}
+	}
+	return n, err
+}
+
+func (r *checksumReader) Close() error {
+	return r.rc.Close()
+}
+
+func aesKeys(password string, salt []byte, keyLen int) (encKey, macKey, verifier []byte) {
+	keys := pbkdf2.Key([]byte(password), salt, aesIterations, 2*keyLen+aesVerifierLen, sha1.New)
+	return keys[:keyLen], keys[keyLen : 2*keyLen], keys[2*keyLen:]
+}
+
+// winzipCTR — режим CTR в варианте WinZip: счётчик little-endian и
+// начинается с единицы, поэтому cipher.NewCTR не подходит.
+type winzipCTR struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+}
+
+func newWinzipCTR(key []byte) (*winzipCTR, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &winzipCTR{block: block, used: aes.BlockSize}, nil
+}
+
+func (c *winzipCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.used]
+		c.used++
+	}
+}
+
+type aesReader struct {
+	r   io.Reader
+	raw io.Reader
+	ctr *winzipCTR
+	mac hash.Hash
+}
+
+func newAESReader(raw io.Reader, compressedSize uint64, password string, keyLen int) (io.Reader, error) {
+	saltLen := keyLen / 2
+	overhead := uint64(saltLen + aesVerifierLen + aesMACLen)
+	if compressedSize < overhead {
+		return nil, fmt.Errorf("%w: запись AES слишком короткая", zip.ErrFormat)
+	}
+
+	header := make([]byte, saltLen+aesVerifierLen)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	encKey, macKey, verifier := aesKeys(password, header[:saltLen], keyLen)
+	if !bytes.Equal(verifier, header[saltLen:]) {
+		return nil, ErrWrongPassword
+	}
+
+	ctr, err := newWinzipCTR(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &aesReader{
+		r:   io.LimitReader(raw, int64(compressedSize-overhead)),
+		raw: raw,
+		ctr: ctr,
+		mac: hmac.New(sha1.New, macKey),
+	}, nil
+}
+
+func (r *aesReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.mac.Write(p[:n])
+	r.ctr.XORKeyStream(p[:n], p[:n])
+	if errors.Is(err, io.EOF) {
+		code := make([]byte, aesMACLen)
+		if _, err := io.ReadFull(r.raw, code); err != nil {
+			return n, err
+		}
+		if !hmac.Equal(code, r.mac.Sum(nil)[:aesMACLen]) {
+			return n, fmt.Errorf("%w: код аутентификации AES не совпал", zip.ErrChecksum)
+		}
+	}
+	return n, err
+}
+
+// zipCryptoKeys — состояние традиционного шифрования PKWARE.
+type zipCryptoKeys [3]uint32
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	keys := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for _, b := range []byte(password) {
+		keys.update(b)
+	}
+	return keys
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32.IEEETable[byte(k[0])^b] ^ (k[0] >> 8)
+	k[1] = (k[1]+(k[0]&0xff))*134775813 + 1
+	k[2] = crc32.IEEETable[byte(k[2])^byte(k[1]>>24)] ^ (k[2] >> 8)
+}
+
+func (k *zipCryptoKeys) decrypt(buf []byte) {
+	for i, c := range buf {
+		temp := k[2] | 2
+		b := c ^ byte((temp*(temp^1))>>8)
+		k.update(b)
+		buf[i] = b
+	}
+}
+
+type zipCryptoReader struct {
+	r    io.Reader
+	keys *zipCryptoKeys
+}
+
+func newZipCryptoReader(raw io.Reader, file *zip.File, password string) (io.Reader, error) {
+	if file.CompressedSize64 < zipCryptoHeaderLen {
+		return nil, fmt.Errorf("%w: запись ZipCrypto слишком короткая", zip.ErrFormat)
+	}
+
+	header := make([]byte, zipCryptoHeaderLen)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	keys := newZipCryptoKeys(password)
+	keys.decrypt(header)
+
+	// Последний байт заголовка — старший байт CRC или, если размеры
+	// записаны после данных, старший байт времени изменения.
+	check := byte(file.CRC32 >> 24)
+	if file.Flags&flagDataDescriptor != 0 {
+		check = byte(file.ModifiedTime >> 8)
+	}
+	if header[zipCryptoHeaderLen-1] != check {
+		return nil, ErrWrongPassword
+	}
+	return &zipCryptoReader{r: raw, keys: keys}, nil
+}
+
+func (r *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.keys.decrypt(p[:n])
+	return n, err
+}
+
+type aesWriter struct {
+	w       io.Writer
+	ctr     *winzipCTR
+	mac     hash.Hash
+	buf     []byte
+	written int64
+}
+
+func (w *aesWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf[:0], p...)
+	w.ctr.XORKeyStream(w.buf, w.buf)
+	w.mac.Write(w.buf)
+	n, err := w.w.Write(w.buf)
+	w.written += int64(n)
+	return n, err
+}
+
+func aesExtraField(method uint16) []byte {
+	field := make([]byte, 11)
+	binary.LittleEndian.PutUint16(field, extraAES)
+	binary.LittleEndian.PutUint16(field[2:], 7)
+	binary.LittleEndian.PutUint16(field[4:], 2)
+	copy(field[6:], "AE")
+	field[8] = aes256Strength
+	binary.LittleEndian.PutUint16(field[9:], method)
+	return field
+}
+
+// setRawModified заполняет время изменения так же, как это делает
+// zip.Writer.CreateHeader: CreateRaw пишет заголовок как есть.
+func setRawModified(header *zip.FileHeader) {
+	if header.Modified.IsZero() {
+		return
+	}
+	t := header.Modified
+	header.ModifiedDate = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	header.ModifiedTime = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+
+	field := make([]byte, 9)
+	binary.LittleEndian.PutUint16(field, extraTimestamp)
+	binary.LittleEndian.PutUint16(field[2:], 5)
+	field[4] = 1 // задано только время изменения
+	binary.LittleEndian.PutUint32(field[5:], uint32(t.Unix()))
+	header.Extra = append(header.Extra, field...)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package fsops
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Образцы в testdata собраны не этим пакетом: ae2.zip — по спецификации
+// WinZip AES (AE-2, AES-256, deflate) независимой реализацией, а
+// zipcrypto.zip — утилитой zip из Info-ZIP с ключом -P.
+var (
+	ae2Text       = strings.Repeat("Привет из WinZip AES-256, вариант AE-2.\n", 3)
+	zipCryptoText = strings.Repeat("Старый ZipCrypto из Info-ZIP.\n", 3)
+)
+
+func readZipEntry(archivePath, password string) (string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	registerZstd(&r.Reader)
+
+	rc, err := openZipFile(r.File[0], password)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	return string(data), err
+}
+
+func TestOpenZipFileSamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		archive  string
+		password string
+		want     string
+		err      error
+	}{
+		{"AE-2", "ae2.zip", "secret", ae2Text, nil},
+		{"AE-2 неверный пароль", "ae2.zip", "wrong", "", ErrWrongPassword},
+		{"AE-2 без пароля", "ae2.zip", "", "", ErrNeedPassword},
+		{"ZipCrypto", "zipcrypto.zip", "secret", zipCryptoText, nil},
+		{"ZipCrypto неверный пароль", "zipcrypto.zip", "wrong", "", ErrWrongPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readZipEntry(filepath.Join("testdata", tt.archive), tt.password)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("data = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenZipFileTampered проверяет, что изменённые данные AE-2
+// отклоняются по коду аутентификации, хотя CRC в AE-2 не хранится.
+func TestOpenZipFileTampered(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ae2.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.OpenReader(filepath.Join("testdata", "ae2.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset, err := r.File[0].DataOffset()
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Первый байт после соли и проверочного значения — шифротекст.
+	data[offset+aes256SaltLen+aesVerifierLen] ^= 0xff
+	archivePath := filepath.Join(t.TempDir(), "tampered.zip")
+	if err := os.WriteFile(archivePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readZipEntry(archivePath, "secret"); err == nil {
+		t.Fatal("изменённые данные прочитаны без ошибки")
+	}
+}
+
+func TestAESRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		compression Compression
+	}{
+		{"пустой файл", "", DefaultCompression},
+		{"store", "короткий текст", Compression{Method: zip.Store}},
+		{"deflate", strings.Repeat("повторяющийся текст ", 5000), DefaultCompression},
+		{"zstd", strings.Repeat("zstd ", 5000), Compression{Method: ZipZstd, Level: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "file.txt")
+			if err := os.WriteFile(src, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			archivePath := filepath.Join(dir, "a.zip")
+			if err := CreateZip(archivePath); err != nil {
+				t.Fatal(err)
+			}
+			opts := AddOptions{Password: "пароль", Compression: &tt.compression}
+			if _, err := AddToZip(archivePath, []string{src}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := zip.OpenReader(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file := r.File[0]
+			r.Close()
+			extra, ok := parseAESExtra(file.Extra)
+			if file.Method != methodAES || !ok || extra.version != 2 || extra.strength != aes256Strength || extra.method != tt.compression.Method {
+				t.Errorf("заголовок: method %d, extra %+v", file.Method, extra)
+			}
+
+			got, err := readZipEntry(archivePath, "пароль")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.data {
+				t.Errorf("data = %q, want %q", got, tt.data)
+			}
+			if _, err := readZipEntry(archivePath, "другой"); !errors.Is(err, ErrWrongPassword) {
+				t.Errorf("err = %v, want %v", err, ErrWrongPassword)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package fsops
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RemoveFromZip удаляет из архива записи, совпадающие с patterns: точные
+// имена или шаблоны, как в AddOptions.Include. Совпавшая папка удаляется
+// вместе с содержимым. Остальные записи копируются без перепаковки.
+func RemoveFromZip(archivePath string, patterns []string) ([]string, error) {
+	var removed []string
+
+	err := rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		var dirs []string
+		for _, file := range src.File {
+			if strings.HasSuffix(file.Name, "/") && matchEntry(patterns, file.Name) {
+				dirs = append(dirs, file.Name)
+			}
+		}
+
+		for _, file := range src.File {
+			if matchEntry(patterns, file.Name) || hasAnyPrefix(file.Name, dirs) {
+				removed = append(removed, file.Name)
+				continue
+			}
+			if err := dst.Copy(file); err != nil {
+				return err
+			}
+		}
+
+		if len(removed) == 0 {
+			return ErrEntryNotFound
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// RenameInZip переименовывает запись oldName в newName. Если oldName —
+// папка, переносятся все записи внутри неё, а сама запись папки
+// сохраняет "/" в конце. Если имя newName в архиве уже занято,
+// возвращается ErrAlreadyExists. Данные записей копируются без
+// перепаковки, меняются только заголовки.
+func RenameInZip(archivePath, oldName, newName string) ([]string, error) {
+	if err := validEntryName(newName); err != nil {
+		return nil, wrap("переименование в архиве", archivePath, err)
+	}
+
+	oldName = strings.TrimSuffix(oldName, "/")
+	newName = strings.TrimSuffix(newName, "/")
+	oldDir := oldName + "/"
+	newDir := newName + "/"
+	switch {
+	case oldName == newName:
+		return nil, wrap("переименование в архиве", archivePath, ErrSameFile)
+	case strings.HasPrefix(newDir, oldDir):
+		return nil, wrap("переименование в архиве", archivePath, ErrIntoItself)
+	}
+	var renamed []string
+
+	err := rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		targets := make(map[*zip.File]string)
+		for _, file := range src.File {
+			switch {
+			case file.Name == oldName:
+				targets[file] = newName
+			case strings.HasPrefix(file.Name, oldDir):
+				targets[file] = newDir + strings.TrimPrefix(file.Name, oldDir)
+			}
+		}
+		if len(targets) == 0 {
+			return ErrEntryNotFound
+		}
+
+		// Имя newName занято, если в архиве уже есть такой файл, папка
+		// или записи внутри неё.
+		for _, file := range src.File {
+			if file.Name == newName || strings.HasPrefix(file.Name, newDir) {
+				return fmt.Errorf("%s: %w", newName, ErrAlreadyExists)
+			}
+		}
+
+		for _, file := range src.File {
+			target, ok := targets[file]
+			if !ok {
+				if err := dst.Copy(file); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := copyRenamed(dst, file, target); err != nil {
+				return err
+			}
+			renamed = append(renamed, file.Name+" -> "+target)
+		}
+		return nil
+	})
+	return renamed, err
+}
+
+func copyRenamed(dst *zip.Writer, file *zip.File, name string) error {
+	header := file.FileHeader
+	header.Name = name
+	if !isASCII(name) && utf8.ValidString(name) {
+		header.Flags |= flagUTF8
+	}
+	return copyRaw(dst, file, &header)
+}
+
+func fileByName(r *zip.Reader, name string) *zip.File {
+	for _, file := range r.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+// matchEntry сравнивает имя записи с patterns. Имя без символов шаблона
+// совпадает только с записью целиком или с папкой того же имени; по
+// базовому имени в любой папке ищут лишь настоящие шаблоны.
+func matchEntry(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if pattern == name || strings.TrimSuffix(pattern, "/")+"/" == name {
+				return true
+			}
+			continue
+		}
+		if matchAny([]string{pattern}, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validEntryName проверяет, что имя записи можно безопасно распаковать.
+func validEntryName(name string) error {
+	_, err := entryPath(".", name)
+	return err
+}
@@ -0,0 +1,122 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchEntry(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"readme.txt", "readme.txt", true},
+		{"readme.txt", "docs/readme.txt", false},
+		{"docs/readme.txt", "docs/readme.txt", true},
+		{"docs", "docs/", true},
+		{"docs/", "docs/", true},
+		{"docs", "docs/readme.txt", false},
+		{"*.txt", "docs/readme.txt", true},
+		{"docs/*.txt", "docs/readme.txt", true},
+		{"docs/*.txt", "src/readme.txt", false},
+		{"**/readme.txt", "src/a/readme.txt", true},
+		{"read?e.txt", "readme.txt", true},
+		{"[rs]eadme.txt", "src/readme.txt", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchEntry([]string{tt.pattern}, tt.name); got != tt.want {
+			t.Errorf("matchEntry(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenameInZipUTF8(t *testing.T) {
+	archivePath := writeZip(t, zip.Deflate, zipEntry{name: "a.txt", data: []byte("a")})
+	if _, err := RenameInZip(archivePath, "a.txt", "отчёт.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if len(r.File) != 1 || r.File[0].Name != "отчёт.txt" {
+		t.Fatalf("records = %v", r.File)
+	}
+	if r.File[0].Flags&flagUTF8 == 0 {
+		t.Error("UTF-8 flag is not set")
+	}
+}
+
+func TestRenameInZipDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+		err      error
+	}{
+		{"папка", "proj/docs/", "newdocs", []string{"newdocs/", "newdocs/a.txt", "newdocs/sub/b.txt", "proj/main.go"}, nil},
+		{"папка без слэша", "proj/docs", "newdocs/", []string{"newdocs/", "newdocs/a.txt", "newdocs/sub/b.txt", "proj/main.go"}, nil},
+		{"файл", "proj/main.go", "main.go", []string{"main.go", "proj/docs/", "proj/docs/a.txt", "proj/docs/sub/b.txt"}, nil},
+		{"занятое имя файла", "proj/docs/a.txt", "proj/main.go", nil, ErrAlreadyExists},
+		{"занятое имя папки", "proj/main.go", "proj/docs", nil, ErrAlreadyExists},
+		{"папка внутрь себя", "proj/docs", "proj/docs/sub/x", nil, ErrIntoItself},
+		{"нет записи", "proj/missing", "x", nil, ErrEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := writeZip(t, zip.Deflate,
+				zipEntry{name: "proj/docs/"},
+				zipEntry{name: "proj/docs/a.txt", data: []byte("a")},
+				zipEntry{name: "proj/docs/sub/b.txt", data: []byte("b")},
+				zipEntry{name: "proj/main.go", data: []byte("m")},
+			)
+			before, err := os.ReadFile(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = RenameInZip(archivePath, tt.old, tt.new)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				after, _ := os.ReadFile(archivePath)
+				if !bytes.Equal(before, after) {
+					t.Error("архив изменён при ошибке")
+				}
+				return
+			}
+
+			r, err := zip.OpenReader(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			var got []string
+			for _, file := range r.File {
+				got = append(got, file.Name)
+				if strings.HasSuffix(file.Name, "/") != file.FileInfo().IsDir() {
+					t.Errorf("%s: IsDir = %v", file.Name, file.FileInfo().IsDir())
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+			if err := fs.WalkDir(r, ".", func(_ string, _ fs.DirEntry, err error) error { return err }); err != nil {
+				t.Errorf("WalkDir: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+package fsops
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ratioThreshold — размер записи, начиная с которого проверяется степень
+// сжатия: маленькие файлы из повторяющихся байтов легально сжимаются в
+// сотни раз.
+const ratioThreshold = 1 << 20
+
+// ExtractLimits защищают от zip-бомб. Нулевое значение поля отключает
+// соответствующую проверку. Размеры проверяются по фактически
+// распакованным байтам, а не по заявленным в заголовках.
+type ExtractLimits struct {
+	MaxTotalSize int64
+	MaxEntries   int
+	MaxRatio     float64
+}
+
+var DefaultExtractLimits = ExtractLimits{
+	MaxTotalSize: 4 << 30,
+	MaxEntries:   100000,
+	MaxRatio:     200,
+}
+
+type ExtractOptions struct {
+	// Patterns отбирают записи так же, как AddOptions.Include;
+	// пустой список означает весь архив. Если какому-то шаблону не
+	// нашлось ни одной записи, распаковка не начинается и возвращается
+	// ErrEntryNotFound.
+	Patterns   []string
+	Limits     ExtractLimits
+	OnConflict func(src, dst string) ConflictAction
+	// Password расшифровывает записи zip, защищённые AES или ZipCrypto.
+	Password string
+	// Ctx прерывает распаковку; всё, что она успела создать, удаляется.
+	// Progress, если задан, получает сведения о ходе операции.
+	Ctx      context.Context
+	Progress func(ProgressInfo)
+}
+
+type ExtractSummary struct {
+	Extracted []string
+	Files     int
+	Dirs      int
+	Symlinks  int
+	Skipped   int
+	Bytes     int64
+}
+
+// ExtractZip распаковывает архив в папку destDir, воссоздавая структуру
+// папок, права доступа и время изменения. Записи с абсолютными путями,
+// "..", а также ссылки и папки, ведущие за пределы destDir, отклоняются
+// с ошибкой ErrUnsafePath до записи чего-либо на диск.
+func ExtractZip(archivePath, destDir string, opts ExtractOptions) (ExtractSummary, error) {
+	var summary ExtractSummary
+
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return summary, wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	entries := make([]extractEntry, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		entries = append(entries, zipExtractEntry(file, opts.Password))
+	}
+
+	selected, err := selectEntries(archivePath, destDir, opts, entries, &summary)
+	if err != nil {
+		return summary, err
+	}
+	if err := checkPassword(zipReader.File, selected, opts.Password); err != nil {
+		return summary, wrap("распаковка", archivePath, err)
+	}
+	x, err := newExtractor(destDir, opts, &summary, entries, selected)
+	if err != nil {
+		return summary, err
+	}
+	for _, entry := range entries {
+		if !selected[entry.name] {
+			continue
+		}
+		if err := x.extract(entry); err != nil {
+			return summary, x.abort(wrap("распаковка", entry.name, err))
+		}
+	}
+	x.finish()
+	return summary, nil
+}
+
+// checkPassword проверяет пароль на первой зашифрованной записи, чтобы
+// неверный пароль обнаружился до записи чего-либо на диск.
+func checkPassword(files []*zip.File, selected map[string]bool, password string) error {
+	for _, file := range files {
+		if !selected[file.Name] || file.Flags&flagEncrypted == 0 {
+			continue
+		}
+		reader, err := openZipFile(file, password)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		return reader.Close()
+	}
+	return nil
+}
+
+// selectEntries отбирает записи по шаблонам и до распаковки проверяет
+// их имена и заявленные размеры.
+func selectEntries(archivePath, destDir string, opts ExtractOptions, entries []extractEntry, summary *ExtractSummary) (map[string]bool, error) {
+	selected := make(map[string]bool, len(entries))
+	matched := make([]bool, len(opts.Patterns))
+	var declared uint64
+	for _, entry := range entries {
+		if len(opts.Patterns) > 0 && !matchPatterns(opts.Patterns, entry.name, matched) {
+			summary.Skipped++
+			continue
+		}
+		if _, err := entryPath(destDir, entry.name); err != nil {
+			return nil, wrap("распаковка", entry.name, err)
+		}
+		selected[entry.name] = true
+		declared += entry.size
+	}
+
+	for i, ok := range matched {
+		if !ok {
+			return nil, wrap("распаковка", opts.Patterns[i], ErrEntryNotFound)
+		}
+	}
+
+	if err := checkLimits(opts.Limits, len(selected), declared); err != nil {
+		return nil, wrap("распаковка", archivePath, err)
+	}
+	return selected, nil
+}
+
+// checkLimits проверяет число записей и их заявленный общий размер до
+// начала распаковки.
+func checkLimits(limits ExtractLimits, entries int, declared uint64) error {
+	if limits.MaxEntries > 0 && entries > limits.MaxEntries {
+		return fmt.Errorf("%w: %d записей при допустимых %d", ErrLimitExceeded, entries, limits.MaxEntries)
+	}
+	if limits.MaxTotalSize > 0 && declared > uint64(limits.MaxTotalSize) {
+		return fmt.Errorf("%w: заявлено %d байт при допустимых %d", ErrLimitExceeded, declared, limits.MaxTotalSize)
+	}
+	return nil
+}
+
+// matchPatterns работает как matchAny, но отмечает в matched каждый
+// шаблон, с которым совпало name.
+func matchPatterns(patterns []string, name string, matched []bool) bool {
+	found := false
+	for i, pattern := range patterns {
+		if matchAny([]string{pattern}, name) {
+			matched[i], found = true, true
+		}
+	}
+	return found
+}
+
+func newExtractor(destDir string, opts ExtractOptions, summary *ExtractSummary, entries []extractEntry, selected map[string]bool) (*extractor, error) {
+	var bytes int64
+	var files int
+	for _, entry := range entries {
+		if selected[entry.name] && !entry.mode.IsDir() {
+			bytes += int64(entry.size)
+			files++
+		}
+	}
+
+	x := &extractor{
+		opts:      opts,
+		summary:   summary,
+		remaining: opts.Limits.MaxTotalSize,
+		tracker:   newTracker(opts.Ctx, opts.Progress, bytes, files),
+	}
+	if err := x.mkdirAll(destDir); err != nil {
+		return nil, wrap("распаковка", destDir, err)
+	}
+	realDest, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return nil, wrap("распаковка", destDir, err)
+	}
+	x.dest = realDest
+	return x, nil
+}
+
+// ExtractZipEntry распаковывает одну запись name в файл destPath.
+// Из opts используются лимиты и пароль.
+func ExtractZipEntry(archivePath, name, destPath string, opts ExtractOptions) error {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	for _, file := range zipReader.File {
+		if file.Name != name {
+			continue
+		}
+		if file.FileInfo().IsDir() {
+			return wrap("распаковка", name, ErrUnsupported)
+		}
+
+		entry := zipExtractEntry(file, opts.Password)
+		x := newEntryExtractor(opts, entry)
+		defer x.tracker.finish()
+		return wrap("распаковка", name, x.writeFile(entry, destPath, 0644))
+	}
+
+	return wrap("поиск в архиве", archivePath, ErrEntryNotFound)
+}
+
+// extractEntry описывает запись архива независимо от его формата.
+type extractEntry struct {
+	name     string
+	mode     fs.FileMode
+	modified time.Time
+	size     uint64
+	// compressed — размер сжатых данных записи или -1, если формат
+	// сжимает архив целиком и степень сжатия записи неизвестна.
+	compressed int64
+	// linkTarget задан, если формат хранит цель ссылки в заголовке,
+	// а не в данных записи.
+	linkTarget string
+	open       func() (io.ReadCloser, error)
+}
+
+func zipExtractEntry(file *zip.File, password string) extractEntry {
+	return extractEntry{
+		name:       file.Name,
+		mode:       file.Mode(),
+		modified:   file.Modified,
+		size:       file.UncompressedSize64,
+		compressed: int64(file.CompressedSize64),
+		open: func() (io.ReadCloser, error) {
+			return openZipFile(file, password)
+		},
+	}
+}
+
+func newEntryExtractor(opts ExtractOptions, entry extractEntry) *extractor {
+	return &extractor{
+		opts:      opts,
+		summary:   &ExtractSummary{},
+		remaining: opts.Limits.MaxTotalSize,
+		tracker:   newTracker(opts.Ctx, opts.Progress, int64(entry.size), 1),
+	}
+}
+
+// entryPath проверяет имя записи и возвращает путь назначения.
+func entryPath(destDir, name string) (string, error) {
+	if name == "" || strings.Contains(name, `\`) || path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+		}
+	}
+
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	if !isWithin(destDir, target) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return target, nil
+}
+
+type extractedDir struct {
+	path     string
+	mode     fs.FileMode
+	modified time.Time
+}
+
+type extractor struct {
+	dest      string
+	opts      ExtractOptions
+	summary   *ExtractSummary
+	remaining int64
+	dirs      []extractedDir
+	tracker   *tracker
+	// created — файлы и папки, появившиеся при распаковке; при отмене
+	// они удаляются в обратном порядке.
+	created []string
+}
+
+func (x *extractor) extract(entry extractEntry) error {
+	if err := x.tracker.err(); err != nil {
+		return err
+	}
+	target, err := entryPath(x.dest, entry.name)
+	if err != nil {
+		return err
+	}
+	if err := x.checkParent(target); err != nil {
+		return err
+	}
+
+	switch mode := entry.mode; {
+	case mode.IsDir():
+		if err := x.mkdirAll(target); err != nil {
+			return err
+		}
+		x.dirs = append(x.dirs, extractedDir{path: target, mode: dirPerm(mode), modified: entry.modified})
+		x.summary.Dirs++
+		x.summary.Extracted = append(x.summary.Extracted, entry.name)
+		return nil
+	case mode&fs.ModeSymlink != 0:
+		return x.writeSymlink(entry, target)
+	case mode.IsRegular():
+		target, ok, err := x.resolveConflict(entry.name, target)
+		if err != nil || !ok {
+			return err
+		}
+		if err := x.writeFile(entry, target, filePerm(mode)); err != nil {
+			return err
+		}
+		x.summary.Files++
+		x.summary.Extracted = append(x.summary.Extracted, entry.name)
+		return nil
+	default:
+		x.summary.Skipped++
+		return nil
+	}
+}
+
+// finish выставляет права папок. Это делается в конце, чтобы папка без
+// права записи не помешала распаковать её содержимое.
+func (x *extractor) finish() {
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		os.Chmod(x.dirs[i].path, x.dirs[i].mode)
+		os.Chtimes(x.dirs[i].path, x.dirs[i].modified, x.dirs[i].modified)
+	}
+	x.tracker.finish()
+}
+
+// abort завершает неудачную распаковку. Если её прервали, созданные
+// файлы и папки удаляются, а уже существовавшие остаются.
+func (x *extractor) abort(err error) error {
+	if isCanceled(err) {
+		for i := len(x.created) - 1; i >= 0; i-- {
+			os.RemoveAll(x.created[i])
+		}
+	}
+	x.tracker.finish()
+	return err
+}
+
+// mkdirAll работает как os.MkdirAll и запоминает верхнюю из созданных
+// папок, чтобы при отмене удалить её вместе с содержимым.
+func (x *extractor) mkdirAll(dir string) error {
+	top := ""
+	for p := dir; ; p = filepath.Dir(p) {
+		if _, err := os.Lstat(p); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		top = p
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if top != "" {
+		x.created = append(x.created, top)
+	}
+	return nil
+}
+
+// checkParent создаёт родительские папки и убеждается, что ни одна из них
+// не является ссылкой за пределы папки назначения.
+func (x *extractor) checkParent(target string) error {
+	parent := filepath.Dir(target)
+	if err := x.mkdirAll(parent); err != nil {
+		return err
+	}
+	realParent, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return err
+	}
+	if !isWithin(x.dest, realParent) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, target)
+	}
+	return nil
+}
+
+func (x *extractor) resolveConflict(name, target string) (string, bool, error) {
+	if _, err := os.Lstat(target); errors.Is(err, fs.ErrNotExist) {
+		return target, true, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	action := ConflictFail
+	if x.opts.OnConflict != nil {
+		action = x.opts.OnConflict(name, target)
+	}
+
+	switch action {
+	case ConflictSkip:
+		x.summary.Skipped++
+		return "", false, nil
+	case ConflictRename:
+		return freeName(target), true, nil
+	case ConflictOverwrite:
+		return target, true, os.RemoveAll(target)
+	default:
+		return "", false, fmt.Errorf("%s: %w", target, ErrAlreadyExists)
+	}
+}
+
+func (x *extractor) writeSymlink(entry extractEntry, target string) error {
+	linkTarget, err := entry.readLink()
+	if err != nil {
+		return err
+	}
+
+	resolved := string(linkTarget)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(target), resolved)
+	}
+	if !isWithin(x.dest, resolved) {
+		return fmt.Errorf("%w: ссылка на %s", ErrUnsafePath, linkTarget)
+	}
+
+	target, ok, err := x.resolveConflict(entry.name, target)
+	if err != nil || !ok {
+		return err
+	}
+	if err := os.Symlink(linkTarget, target); err != nil {
+		return err
+	}
+	x.created = append(x.created, target)
+	x.tracker.fileDone()
+	x.summary.Symlinks++
+	x.summary.Extracted = append(x.summary.Extracted, entry.name)
+	return nil
+}
+
+func (e extractEntry) readLink() (string, error) {
+	if e.linkTarget != "" {
+		return e.linkTarget, nil
+	}
+	if e.size > 4096 {
+		return "", fmt.Errorf("%w: слишком длинная ссылка", ErrUnsafePath)
+	}
+
+	reader, err := e.open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	linkTarget, err := io.ReadAll(io.LimitReader(reader, 4097))
+	if err != nil {
+		return "", err
+	}
+	if len(linkTarget) > 4096 {
+		return "", fmt.Errorf("%w: слишком длинная ссылка", ErrUnsafePath)
+	}
+	return string(linkTarget), nil
+}
+
+func (x *extractor) writeFile(entry extractEntry, target string, perm fs.FileMode) error {
+	reader, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	x.tracker.begin(entry.name)
+	written, err := io.Copy(out, x.tracker.reader(&limitReader{r: reader, limit: readLimit(x.opts.Limits, x.remaining, entry.compressed)}))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return err
+	}
+
+	x.created = append(x.created, target)
+	x.tracker.fileDone()
+	x.remaining -= written
+	x.summary.Bytes += written
+	if err := os.Chmod(target, perm); err != nil {
+		return err
+	}
+	return os.Chtimes(target, entry.modified, entry.modified)
+}
+
+// readLimit возвращает, сколько байт можно распаковать из записи,
+// сжатой в compressed байт (-1, если неизвестно), когда до общего
+// лимита осталось remaining, или -1, если ограничений нет.
+func readLimit(limits ExtractLimits, remaining, compressed int64) int64 {
+	limit := int64(-1)
+	if limits.MaxTotalSize > 0 {
+		limit = max(remaining, 0)
+	}
+	if limits.MaxRatio > 0 && compressed >= 0 {
+		ratioLimit := max(int64(limits.MaxRatio*float64(compressed)), ratioThreshold)
+		if limit < 0 || ratioLimit < limit {
+			limit = ratioLimit
+		}
+	}
+	return limit
+}
+
+type limitReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.limit >= 0 && l.read > l.limit {
+		return n, fmt.Errorf("%w: распаковано больше %d байт", ErrLimitExceeded, l.limit)
+	}
+	return n, err
+}
+
+func filePerm(mode fs.FileMode) fs.FileMode {
+	if perm := mode.Perm(); perm != 0 {
+		return perm
+	}
+	return 0644
+}
+
+func dirPerm(mode fs.FileMode) fs.FileMode {
+	if perm := mode.Perm(); perm != 0 {
+		return perm | 0700
+	}
+	return 0755
+}
@@ -0,0 +1,147 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExtractZipPatterns(t *testing.T) {
+	archivePath := writeZip(t, zip.Deflate,
+		zipEntry{name: "readme.txt", data: []byte("r")},
+		zipEntry{name: "docs/readme.txt", data: []byte("d")},
+		zipEntry{name: "src/main.go", data: []byte("m")},
+	)
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		err      error
+	}{
+		{"весь архив", nil, []string{"docs/readme.txt", "readme.txt", "src/main.go"}, nil},
+		{"точное имя", []string{"src/main.go"}, []string{"src/main.go"}, nil},
+		{"шаблон", []string{"*.txt"}, []string{"docs/readme.txt", "readme.txt"}, nil},
+		{"опечатка", []string{"src/mian.go"}, nil, ErrEntryNotFound},
+		{"один из шаблонов не совпал", []string{"*.txt", "*.md"}, nil, ErrEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "out")
+			summary, err := ExtractZip(archivePath, dest, ExtractOptions{Patterns: tt.patterns})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				if _, statErr := os.Stat(dest); !errors.Is(statErr, os.ErrNotExist) {
+					t.Errorf("папка %s создана при ошибке", dest)
+				}
+				return
+			}
+			got := slices.Clone(summary.Extracted)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Extracted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractZipUnsafePaths(t *testing.T) {
+	const link = fs.ModeSymlink | 0o777
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		// prepare готовит папку назначения до распаковки.
+		prepare func(t *testing.T, dest, outside string)
+	}{
+		{name: "выход через ..", entries: []zipEntry{{name: "../evil.txt", data: []byte("x")}}},
+		{name: ".. в середине пути", entries: []zipEntry{{name: "a/../../evil.txt", data: []byte("x")}}},
+		{name: "абсолютный путь", entries: []zipEntry{{name: "/tmp/evil.txt", data: []byte("x")}}},
+		{name: "обратная косая черта", entries: []zipEntry{{name: `..\evil.txt`, data: []byte("x")}}},
+		{name: "ссылка наружу", entries: []zipEntry{{name: "link", data: []byte("../outside"), mode: link}}},
+		{name: "ссылка на абсолютный путь", entries: []zipEntry{{name: "link", data: []byte("/etc"), mode: link}}},
+		{
+			name: "запись через ссылку из архива",
+			entries: []zipEntry{
+				{name: "dir", data: []byte("."), mode: link},
+				{name: "up", data: []byte("dir/.."), mode: link},
+				{name: "up/evil.txt", data: []byte("x")},
+			},
+		},
+		{
+			name:    "запись через ссылку в папке назначения",
+			entries: []zipEntry{{name: "out/evil.txt", data: []byte("x")}},
+			prepare: func(t *testing.T, dest, outside string) {
+				if err := os.MkdirAll(dest, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(outside, filepath.Join(dest, "out")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := writeZip(t, zip.Deflate, tt.entries...)
+			root := t.TempDir()
+			dest, outside := filepath.Join(root, "dest"), filepath.Join(root, "outside")
+			if err := os.Mkdir(outside, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, dest, outside)
+			}
+
+			_, err := ExtractZip(archivePath, dest, ExtractOptions{})
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Fatalf("err = %v, want %v", err, ErrUnsafePath)
+			}
+			for _, dir := range []string{root, outside} {
+				if _, err := os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+					t.Errorf("файл записан в %s", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractZipLimits(t *testing.T) {
+	zeros := make([]byte, 2*ratioThreshold)
+	small := bytes.Repeat([]byte("a"), 64<<10)
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		limits  ExtractLimits
+		err     error
+	}{
+		{"степень сжатия превышена", []zipEntry{{name: "zeros.bin", data: zeros}}, ExtractLimits{MaxRatio: 200}, ErrLimitExceeded},
+		{"без ограничения степени сжатия", []zipEntry{{name: "zeros.bin", data: zeros}}, ExtractLimits{}, nil},
+		{"маленький файл сжимается сильно", []zipEntry{{name: "small.bin", data: small}}, ExtractLimits{MaxRatio: 200}, nil},
+		{"общий размер превышен", []zipEntry{{name: "a", data: small}, {name: "b", data: small}}, ExtractLimits{MaxTotalSize: 100 << 10}, ErrLimitExceeded},
+		{"общий размер в пределах", []zipEntry{{name: "a", data: small}, {name: "b", data: small}}, ExtractLimits{MaxTotalSize: 128 << 10}, nil},
+		{"слишком много записей", []zipEntry{{name: "a"}, {name: "b"}, {name: "c"}}, ExtractLimits{MaxEntries: 2}, ErrLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := writeZip(t, zip.Deflate, tt.entries...)
+			dest := filepath.Join(t.TempDir(), "out")
+
+			_, err := ExtractZip(archivePath, dest, ExtractOptions{Limits: tt.limits})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package fsops
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ZipFS представляет архив zip как файловую систему только для чтения.
+// В отличие от zip.Reader, ZipFS расшифровывает записи паролем и
+// читает записи, сжатые zstd.
+type ZipFS struct {
+	reader   *zip.ReadCloser
+	files    map[string]*zip.File
+	dirs     map[string]*zip.File
+	password string
+}
+
+func OpenZipFS(archivePath, password string) (*ZipFS, error) {
+	reader, err := openZipReader(archivePath)
+	if err != nil {
+		return nil, wrap("открытие архива", archivePath, err)
+	}
+
+	files := make(map[string]*zip.File, len(reader.File))
+	dirs := make(map[string]*zip.File)
+	for _, file := range reader.File {
+		name := strings.TrimSuffix(file.Name, "/")
+		if !fs.ValidPath(name) {
+			continue
+		}
+		if name != file.Name {
+			dirs[name] = file
+		} else {
+			files[name] = file
+		}
+	}
+	return &ZipFS{reader: reader, files: files, dirs: dirs, password: password}, nil
+}
+
+// Open открывает файл или папку; имена — пути внутри архива без
+// начального "/", корень архива — ".".
+func (z *ZipFS) Open(name string) (fs.File, error) {
+	file, ok := z.files[name]
+	if !ok {
+		// Папки, в том числе не записанные в архив явно, строит zip.Reader.
+		f, err := z.reader.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		if dir, ok := f.(fs.ReadDirFile); ok {
+			return &zipFSDir{ReadDirFile: dir, fsys: z, name: name}, nil
+		}
+		return f, nil
+	}
+
+	reader, err := openZipFile(file, z.password)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &zipFSFile{ReadCloser: reader, info: file.FileInfo()}, nil
+}
+
+// ReadDir и Stat берут права и время папок из их записей в архиве:
+// zip.Reader показывает все папки с правами 0555.
+func (z *ZipFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(z.reader, name)
+	z.fixDirEntries(name, entries)
+	return entries, err
+}
+
+func (z *ZipFS) fixDirEntries(name string, entries []fs.DirEntry) {
+	for i, entry := range entries {
+		if file, ok := z.dirs[path.Join(name, entry.Name())]; ok {
+			entries[i] = fs.FileInfoToDirEntry(file.FileInfo())
+		}
+	}
+}
+
+func (z *ZipFS) Stat(name string) (fs.FileInfo, error) {
+	if file, ok := z.dirs[name]; ok {
+		return file.FileInfo(), nil
+	}
+	// zip.Reader открывает запись ради Stat, а зашифрованные записи он
+	// открыть не может.
+	if file, ok := z.files[name]; ok {
+		return file.FileInfo(), nil
+	}
+	return fs.Stat(z.reader, name)
+}
+
+func (z *ZipFS) Close() error {
+	return z.reader.Close()
+}
+
+type zipFSFile struct {
+	io.ReadCloser
+	info fs.FileInfo
+}
+
+func (f *zipFSFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// zipFSDir — папка, открытая через ZipFS.Open; права и время берутся
+// из записи папки, как в ZipFS.Stat.
+type zipFSDir struct {
+	fs.ReadDirFile
+	fsys *ZipFS
+	name string
+}
+
+func (d *zipFSDir) Stat() (fs.FileInfo, error) {
+	if file, ok := d.fsys.dirs[d.name]; ok {
+		return file.FileInfo(), nil
+	}
+	return d.ReadDirFile.Stat()
+}
+
+func (d *zipFSDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := d.ReadDirFile.ReadDir(n)
+	d.fsys.fixDirEntries(d.name, entries)
+	return entries, err
+}
+
+// ListDirFS работает как ListDir, но для папки dir в файловой системе fsys.
+func ListDirFS(fsys fs.FS, dir string) ([]fs.FileInfo, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, wrap("чтение папки", dir, err)
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, wrap("чтение папки", path.Join(dir, entry.Name()), err)
+		}
+		infos = append(infos, info)
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+// TreeFS работает как Tree, но для папки dir в файловой системе fsys.
+func TreeFS(fsys fs.FS, dir string, maxDepth int) (*TreeNode, error) {
+	info, err := fs.Stat(fsys, dir)
+	if err != nil {
+		return nil, wrap("построение дерева", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, wrap("построение дерева", dir, ErrNotDir)
+	}
+
+	root := &TreeNode{Info: info}
+	fillTreeFS(fsys, root, dir, 1, maxDepth)
+	return root, nil
+}
+
+func fillTreeFS(fsys fs.FS, node *TreeNode, dir string, depth, maxDepth int) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+
+	infos, err := ListDirFS(fsys, dir)
+	if err != nil {
+		node.Err = err
+		return
+	}
+
+	for _, info := range infos {
+		child := &TreeNode{Info: info}
+		if info.IsDir() {
+			fillTreeFS(fsys, child, path.Join(dir, info.Name()), depth+1, maxDepth)
+		}
+		node.Children = append(node.Children, child)
+	}
+}
+
+// CopyFromFS копирует файл или папку name из fsys в dst на диске,
+// сохраняя права и время изменения. Существующие папки объединяются,
+// конфликты файлов решает opts.OnConflict, а заменяемый файл
+// подменяется только после того, как новый записан целиком. Символические
+// ссылки копируются, только если не ведут за пределы dst. opts.Limits
+// ограничивают копирование так же, как распаковку архива.
+func CopyFromFS(fsys fs.FS, name, dst string, opts TransferOptions) error {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return wrap("копирование", name, err)
+	}
+
+	bytes, files := measureFS(fsys, name, info)
+	if err := checkLimits(opts.Limits, files, uint64(bytes)); err != nil {
+		return wrap("копирование", name, err)
+	}
+
+	// Ссылки не должны выводить за пределы копии, а для отдельного
+	// файла — за пределы папки, в которую он копируется.
+	root := filepath.Clean(dst)
+	if !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	c := &fsCopier{
+		fsys:      fsys,
+		opts:      opts,
+		root:      root,
+		remaining: opts.Limits.MaxTotalSize,
+		tracker:   newTracker(opts.Ctx, opts.Progress, bytes, files),
+	}
+	defer c.tracker.finish()
+
+	if err := c.copy(name, filepath.Clean(dst), info); err != nil {
+		for i := len(c.created) - 1; i >= 0; i-- {
+			os.RemoveAll(c.created[i])
+		}
+		return wrap("копирование", name, err)
+	}
+	return nil
+}
+
+type fsCopier struct {
+	fsys      fs.FS
+	opts      TransferOptions
+	root      string
+	remaining int64
+	tracker   *tracker
+	// created — файлы и папки, которых не было до копирования; при
+	// ошибке они удаляются.
+	created []string
+}
+
+func (c *fsCopier) copy(name, dst string, info fs.FileInfo) error {
+	if err := c.tracker.err(); err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if dstInfo, err := os.Stat(dst); err == nil && !dstInfo.IsDir() {
+			return fmt.Errorf("%s: %w", dst, ErrNotDir)
+		} else if errors.Is(err, fs.ErrNotExist) {
+			if err := os.Mkdir(dst, 0700); err != nil {
+				return err
+			}
+			c.created = append(c.created, dst)
+		} else if err != nil {
+			return err
+		}
+
+		infos, err := ListDirFS(c.fsys, name)
+		if err != nil {
+			return err
+		}
+		for _, child := range infos {
+			if err := c.copy(path.Join(name, child.Name()), filepath.Join(dst, child.Name()), child); err != nil {
+				return err
+			}
+		}
+		// Папки, которых нет в архиве явно, zip.Reader показывает с
+		// правами 0555 и нулевым временем.
+		if err := os.Chmod(dst, dirPerm(info.Mode())); err != nil {
+			return err
+		}
+		if info.ModTime().IsZero() {
+			return nil
+		}
+		return os.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+
+	dst, replace, err := c.resolveConflict(name, dst)
+	if err != nil || dst == "" {
+		return err
+	}
+
+	var write func(string) error
+	switch mode := info.Mode(); {
+	case mode&fs.ModeSymlink != 0:
+		target, err := c.linkTarget(name, dst)
+		if err != nil {
+			return err
+		}
+		write = func(dst string) error { return os.Symlink(target, dst) }
+	case mode.IsRegular():
+		write = func(dst string) error { return c.copyFile(name, dst, info) }
+	default:
+		return fmt.Errorf("%s: %w", name, ErrUnsupported)
+	}
+
+	if replace {
+		err = replaceWith(dst, write)
+	} else if err = write(dst); err == nil {
+		c.created = append(c.created, dst)
+	}
+	if err != nil {
+		return err
+	}
+	c.tracker.fileDone()
+	return nil
+}
+
+// resolveConflict возвращает путь, по которому записать файл, и признак
+// того, что на этом пути уже есть файл и его нужно заменить. Пустой путь
+// означает, что файл пропускается.
+func (c *fsCopier) resolveConflict(name, dst string) (string, bool, error) {
+	if _, err := os.Lstat(dst); errors.Is(err, fs.ErrNotExist) {
+		return dst, false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	action := ConflictFail
+	if c.opts.OnConflict != nil {
+		action = c.opts.OnConflict(name, dst)
+	}
+
+	switch action {
+	case ConflictSkip:
+		return "", false, nil
+	case ConflictRename:
+		return freeName(dst), false, nil
+	case ConflictOverwrite:
+		return dst, true, nil
+	default:
+		return "", false, fmt.Errorf("%s: %w", dst, ErrAlreadyExists)
+	}
+}
+
+func (c *fsCopier) copyFile(name, dst string, info fs.FileInfo) error {
+	in, err := c.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	c.tracker.begin(name)
+	limit := readLimit(c.opts.Limits, c.remaining, compressedSize(info))
+	written, err := io.Copy(out, c.tracker.reader(&limitReader{r: in, limit: limit}))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	c.remaining -= written
+	return preserveAttrs(dst, info)
+}
+
+// linkTarget читает цель ссылки name и проверяет, что ссылка на месте
+// dst не выводит за пределы копии.
+func (c *fsCopier) linkTarget(name, dst string) (string, error) {
+	f, err := c.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, 4097))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > 4096 {
+		return "", fmt.Errorf("%w: слишком длинная ссылка", ErrUnsafePath)
+	}
+
+	target := string(data)
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(dst), resolved)
+	}
+	if !isWithin(c.root, resolved) {
+		return "", fmt.Errorf("%w: ссылка на %s", ErrUnsafePath, target)
+	}
+	return target, nil
+}
+
+// compressedSize возвращает размер сжатых данных записи zip или -1,
+// если info описывает не запись zip.
+func compressedSize(info fs.FileInfo) int64 {
+	if header, ok := info.Sys().(*zip.FileHeader); ok {
+		return int64(header.CompressedSize64)
+	}
+	return -1
+}
+
+func measureFS(fsys fs.FS, name string, info fs.FileInfo) (int64, int) {
+	if !info.IsDir() {
+		return info.Size(), 1
+	}
+
+	var bytes int64
+	var files int
+	fs.WalkDir(fsys, name, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		files++
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return bytes, files
+}
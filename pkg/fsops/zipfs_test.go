@@ -0,0 +1,143 @@
+package fsops
+
+import (
+	"archive/zip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestZipFS(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	files := map[string]string{
+		"docs/readme.txt":    "обычная запись",
+		"docs/sub/notes.txt": "вложенная папка",
+		"secret.txt":         "зашифрованная запись",
+		"packed/data.txt":    "запись zstd",
+	}
+	for name, data := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Права папки отличаются от 0555, которые показывает zip.Reader.
+	if err := os.Chmod(filepath.Join(src, "docs"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath := filepath.Join(dir, "a.zip")
+	if err := CreateZip(archivePath); err != nil {
+		t.Fatal(err)
+	}
+	adds := []struct {
+		path string
+		opts AddOptions
+	}{
+		{"docs", AddOptions{}},
+		{"secret.txt", AddOptions{Password: "пароль"}},
+		{"packed/data.txt", AddOptions{Dir: "packed", Compression: &Compression{Method: ZipZstd, Level: -1}}},
+	}
+	for _, add := range adds {
+		if _, err := AddToZip(archivePath, []string{filepath.Join(src, add.path)}, add.opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zfs, err := OpenZipFS(archivePath, "пароль")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zfs.Close()
+
+	if err := fstest.TestFS(zfs, "docs/readme.txt", "docs/sub/notes.txt", "secret.txt", "packed/data.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := zfs.Stat("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o750 {
+		t.Errorf("docs perm = %v, want 0750", perm)
+	}
+}
+
+func TestCopyFromFS(t *testing.T) {
+	archivePath := writeZip(t, zip.Deflate,
+		zipEntry{name: "docs/a.txt", data: []byte("a")},
+		zipEntry{name: "docs/b.txt", data: []byte("b")},
+		zipEntry{name: "zeros.bin", data: make([]byte, 8<<20)},
+	)
+	zfs, err := OpenZipFS(archivePath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zfs.Close()
+
+	overwrite := func(string, string) ConflictAction { return ConflictOverwrite }
+	tests := []struct {
+		name       string
+		entry      string
+		limits     ExtractLimits
+		existing   []byte
+		onConflict func(src, dst string) ConflictAction
+		want       map[string]string
+		err        error
+	}{
+		{"папка", "docs", DefaultExtractLimits, nil, nil, map[string]string{"a.txt": "a", "b.txt": "b"}, nil},
+		{"zip-бомба", "zeros.bin", DefaultExtractLimits, nil, nil, nil, ErrLimitExceeded},
+		{"zip-бомба внутри папки", ".", DefaultExtractLimits, nil, nil, nil, ErrLimitExceeded},
+		{"без лимитов", "zeros.bin", ExtractLimits{}, nil, nil, map[string]string{".": string(make([]byte, 8<<20))}, nil},
+		{"общий размер", "docs", ExtractLimits{MaxTotalSize: 1}, nil, nil, nil, ErrLimitExceeded},
+		{"число записей", "docs", ExtractLimits{MaxEntries: 1}, nil, nil, nil, ErrLimitExceeded},
+		{"конфликт", "docs/a.txt", DefaultExtractLimits, []byte("old"), nil, map[string]string{".": "old"}, ErrAlreadyExists},
+		{"замена", "docs/a.txt", DefaultExtractLimits, []byte("old"), overwrite, map[string]string{".": "a"}, nil},
+		{"замена с ошибкой", "zeros.bin", DefaultExtractLimits, []byte("old"), overwrite, map[string]string{".": "old"}, ErrLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "out")
+			if tt.existing != nil {
+				if err := os.WriteFile(dst, tt.existing, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CopyFromFS(zfs, tt.entry, dst, TransferOptions{Limits: tt.limits, OnConflict: tt.onConflict})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			got := make(map[string]string)
+			filepath.WalkDir(filepath.Dir(dst), func(path string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dst, path)
+				if err != nil {
+					return err
+				}
+				data, err := os.ReadFile(path)
+				got[filepath.ToSlash(rel)] = string(data)
+				return err
+			})
+			if len(got) != len(tt.want) {
+				t.Fatalf("files = %d, want %d", len(got), len(tt.want))
+			}
+			for name, data := range tt.want {
+				if got[name] != data {
+					t.Errorf("%s: content differs", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package fsops
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"strings"
+	"time"
+)
+
+// extraUnixOwner — поле Info-ZIP с числовыми UID и GID владельца.
+const extraUnixOwner = 0x7875
+
+// EntryMetadata дополняет ArchiveEntry сведениями, которые хранятся
+// в заголовке записи zip и её дополнительных полях.
+type EntryMetadata struct {
+	ArchiveEntry
+	// UID и GID равны -1, если владелец в архиве не сохранён.
+	UID int
+	GID int
+	// Creator — система, в которой создана запись: Unix, MS-DOS и т. п.
+	Creator string
+	// PreciseTime означает, что время изменения взято из расширенной
+	// метки 0x5455 с точностью до секунды, а не из поля MS-DOS с
+	// точностью до двух секунд и без часового пояса.
+	PreciseTime bool
+	// Extra — идентификаторы дополнительных полей записи.
+	Extra []uint16
+}
+
+// ZipComment возвращает комментарий архива.
+func ZipComment(archivePath string) (string, error) {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return "", wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+	return zipReader.Comment, nil
+}
+
+// SetZipComment заменяет комментарий архива; пустая строка удаляет его.
+// Записи копируются без перепаковки.
+func SetZipComment(archivePath, comment string) error {
+	if err := checkCommentLen(comment); err != nil {
+		return wrap("изменение комментария", archivePath, err)
+	}
+	return rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		for _, file := range src.File {
+			if err := dst.Copy(file); err != nil {
+				return err
+			}
+		}
+		return dst.SetComment(comment)
+	})
+}
+
+// ZipEntryMetadata возвращает подробные сведения о записи name.
+func ZipEntryMetadata(archivePath, name string) (EntryMetadata, error) {
+	zipReader, err := openZipReader(archivePath)
+	if err != nil {
+		return EntryMetadata{}, wrap("открытие архива", archivePath, err)
+	}
+	defer zipReader.Close()
+
+	file := fileByName(&zipReader.Reader, name)
+	if file == nil {
+		return EntryMetadata{}, wrap("поиск в архиве", name, ErrEntryNotFound)
+	}
+
+	meta := EntryMetadata{
+		ArchiveEntry: zipArchiveEntry(file),
+		UID:          -1,
+		GID:          -1,
+		Creator:      creatorName(file.CreatorVersion >> 8),
+		Extra:        extraIDs(file.Extra),
+	}
+	if data, ok := findExtra(file.Extra, extraUnixOwner); ok {
+		meta.UID, meta.GID = parseUnixOwner(data)
+	}
+	_, meta.PreciseTime = findExtra(file.Extra, extraTimestamp)
+	return meta, nil
+}
+
+// SetZipEntryComment заменяет комментарий одной записи name; у папки
+// содержимое не затрагивается.
+func SetZipEntryComment(archivePath, name, comment string) error {
+	if err := checkCommentLen(comment); err != nil {
+		return wrap("изменение комментария", name, err)
+	}
+	return rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		target := fileByName(src, name)
+		if target == nil {
+			target = fileByName(src, strings.TrimSuffix(name, "/")+"/")
+		}
+		if target == nil {
+			return ErrEntryNotFound
+		}
+
+		for _, file := range src.File {
+			if file != target {
+				if err := dst.Copy(file); err != nil {
+					return err
+				}
+				continue
+			}
+
+			header := file.FileHeader
+			header.Extra = append([]byte(nil), file.Extra...)
+			header.Comment = comment
+			if !isASCII(comment) {
+				header.Flags |= flagUTF8
+			}
+			if err := copyRaw(dst, file, &header); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetZipModified меняет время изменения записей, совпадающих с patterns,
+// как в RemoveFromZip: у совпавшей папки меняется и её содержимое.
+// Время записывается и в поле MS-DOS, и в расширенную метку 0x5455.
+func SetZipModified(archivePath string, patterns []string, modified time.Time) ([]string, error) {
+	var changed []string
+	err := editZipHeaders(archivePath, patterns, func(header *zip.FileHeader) {
+		header.Modified = modified
+		header.Extra = withoutExtra(header.Extra, extraTimestamp)
+		setRawModified(header)
+		changed = append(changed, header.Name)
+	})
+	return changed, err
+}
+
+// editZipHeaders переписывает архив, меняя заголовки записей, которые
+// совпали с patterns. Данные записей копируются без перепаковки.
+func editZipHeaders(archivePath string, patterns []string, edit func(header *zip.FileHeader)) error {
+	return rewriteZip(archivePath, func(src *zip.Reader, dst *zip.Writer) error {
+		var dirs []string
+		for _, file := range src.File {
+			if file.FileInfo().IsDir() && matchEntry(patterns, file.Name) {
+				dirs = append(dirs, file.Name)
+			}
+		}
+
+		found := false
+		for _, file := range src.File {
+			if !matchEntry(patterns, file.Name) && !hasAnyPrefix(file.Name, dirs) {
+				if err := dst.Copy(file); err != nil {
+					return err
+				}
+				continue
+			}
+
+			found = true
+			header := file.FileHeader
+			header.Extra = append([]byte(nil), file.Extra...)
+			edit(&header)
+			if err := copyRaw(dst, file, &header); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return ErrEntryNotFound
+		}
+		return nil
+	})
+}
+
+// copyRaw переносит сжатые данные file в архив под заголовком header.
+func copyRaw(dst *zip.Writer, file *zip.File, header *zip.FileHeader) error {
+	raw, err := file.OpenRaw()
+	if err != nil {
+		return err
+	}
+	writer, err := dst.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, raw)
+	return err
+}
+
+func checkCommentLen(comment string) error {
+	if len(comment) > math.MaxUint16 {
+		return fmt.Errorf("комментарий длиннее %d байт", math.MaxUint16)
+	}
+	return nil
+}
+
+// unixOwnerField возвращает поле 0x7875 с владельцем файла или nil,
+// если система не сообщает числовых UID и GID.
+func unixOwnerField(info fs.FileInfo) []byte {
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return nil
+	}
+
+	field := make([]byte, 15)
+	binary.LittleEndian.PutUint16(field, extraUnixOwner)
+	binary.LittleEndian.PutUint16(field[2:], 11)
+	field[4] = 1 // версия поля
+	field[5] = 4
+	binary.LittleEndian.PutUint32(field[6:], uint32(uid))
+	field[10] = 4
+	binary.LittleEndian.PutUint32(field[11:], uint32(gid))
+	return field
+}
+
+// parseUnixOwner читает поле 0x7875, где UID и GID хранятся числами
+// переменной длины в порядке little-endian.
+func parseUnixOwner(data []byte) (uid, gid int) {
+	uid, gid = -1, -1
+	if len(data) < 2 || data[0] != 1 {
+		return
+	}
+	read := func(b []byte) (int, []byte, bool) {
+		if len(b) < 1 || int(b[0]) > len(b)-1 || b[0] > 4 {
+			return -1, nil, false
+		}
+		var n uint32
+		for i := int(b[0]); i > 0; i-- {
+			n = n<<8 | uint32(b[i])
+		}
+		return int(n), b[1+int(b[0]):], true
+	}
+
+	u, rest, ok := read(data[1:])
+	if !ok {
+		return
+	}
+	g, _, ok := read(rest)
+	if !ok {
+		return
+	}
+	return u, g
+}
+
+// findExtra возвращает данные дополнительного поля id.
+func findExtra(extra []byte, id uint16) ([]byte, bool) {
+	for len(extra) >= 4 {
+		fieldID := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			return nil, false
+		}
+		if fieldID == id {
+			return extra[:size], true
+		}
+		extra = extra[size:]
+	}
+	return nil, false
+}
+
+// withoutExtra возвращает extra без полей id.
+func withoutExtra(extra []byte, id uint16) []byte {
+	var out []byte
+	for len(extra) >= 4 {
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		if binary.LittleEndian.Uint16(extra) != id {
+			out = append(out, extra[:4+size]...)
+		}
+		extra = extra[4+size:]
+	}
+	return out
+}
+
+func extraIDs(extra []byte) []uint16 {
+	var ids []uint16
+	for len(extra) >= 4 {
+		ids = append(ids, binary.LittleEndian.Uint16(extra))
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		extra = extra[4+size:]
+	}
+	return ids
+}
+
+func creatorName(host uint16) string {
+	switch host {
+	case 0:
+		return "MS-DOS"
+	case 3:
+		return "Unix"
+	case 7:
+		return "Macintosh"
+	case 10:
+		return "NTFS"
+	case 19:
+		return "OS X"
+	default:
+		return fmt.Sprintf("система %d", host)
+	}
+}
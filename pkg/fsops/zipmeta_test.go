@@ -0,0 +1,49 @@
+package fsops
+
+import (
+	"archive/zip"
+	"errors"
+	"testing"
+)
+
+func TestSetZipEntryComment(t *testing.T) {
+	entries := []zipEntry{{name: "docs/"}, {name: "docs/readme.txt", data: []byte("r")}, {name: "readme.txt", data: []byte("r")}}
+
+	tests := []struct {
+		name    string
+		entry   string
+		changed string
+		err     error
+	}{
+		{"файл", "readme.txt", "readme.txt", nil},
+		{"папка", "docs/", "docs/", nil},
+		{"папка без косой черты", "docs", "docs/", nil},
+		{"нет записи", "missing.txt", "", ErrEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := writeZip(t, zip.Store, entries...)
+
+			err := SetZipEntryComment(archivePath, tt.entry, "заметка")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			r, err := zip.OpenReader(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			for _, f := range r.File {
+				want := ""
+				if f.Name == tt.changed {
+					want = "заметка"
+				}
+				if f.Comment != want {
+					t.Errorf("%s: comment = %q, want %q", f.Name, f.Comment, want)
+				}
+			}
+		})
+	}
+}
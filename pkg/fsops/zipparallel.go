@@ -0,0 +1,296 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// spoolMemoryLimit — сколько сжатых данных записи держится в памяти;
+// всё, что больше, уходит во временный файл.
+const spoolMemoryLimit = 1 << 20
+
+// writeZipEntries сжимает записи пулом горутин, а в архив пишет их по
+// одной в исходном порядке, поэтому порядок центрального каталога не
+// зависит от того, какой файл сжался быстрее. Готовых, но ещё не
+// записанных элементов не бывает больше двух на горутину.
+func writeZipEntries(dst *zip.Writer, sources []addSource, opts AddOptions, tr *tracker) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, max(len(sources), 1))
+
+	type result struct {
+		entry *preparedEntry
+		err   error
+	}
+	results := make([]chan result, len(sources))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	jobs := make(chan int)
+	pending := make(chan struct{}, 2*workers)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				entry, err := prepareZipEntry(sources[i], opts, tr)
+				results[i] <- result{entry, err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range sources {
+			select {
+			case pending <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	done := 0
+	for ; done < len(sources); done++ {
+		r := <-results[done]
+		err = r.err
+		if err == nil {
+			err = tr.err()
+		}
+		if err == nil {
+			err = r.entry.write(dst)
+		}
+		if err == nil && !sources[done].info.IsDir() {
+			tr.fileDone()
+		}
+		if r.entry != nil {
+			r.entry.close()
+		}
+		<-pending
+		if err != nil {
+			err = fmt.Errorf("%s: %w", sources[done].path, err)
+			break
+		}
+	}
+
+	if err != nil {
+		close(stop)
+		wg.Wait()
+		for _, ch := range results[done+1:] {
+			select {
+			case r := <-ch:
+				if r.entry != nil {
+					r.entry.close()
+				}
+			default:
+			}
+		}
+	}
+	return err
+}
+
+// preparedEntry — уже сжатая (и, возможно, зашифрованная) запись,
+// которую осталось переписать в архив через CreateRaw.
+type preparedEntry struct {
+	header *zip.FileHeader
+	prefix []byte
+	data   *spool
+	suffix []byte
+}
+
+func prepareZipEntry(source addSource, opts AddOptions, tr *tracker) (*preparedEntry, error) {
+	if err := tr.err(); err != nil {
+		return nil, err
+	}
+
+	header, err := zip.FileInfoHeader(source.info)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = source.name
+	header.ReaderVersion = 20
+	if !isASCII(header.Name) && utf8.ValidString(header.Name) {
+		header.Flags |= flagUTF8
+	}
+	setRawModified(header)
+	header.Extra = append(header.Extra, unixOwnerField(source.info)...)
+
+	entry := &preparedEntry{header: header}
+	mode := source.info.Mode()
+	if mode.IsDir() {
+		header.Method = zip.Store
+		header.UncompressedSize64 = 0
+		return entry, nil
+	}
+
+	var data io.Reader
+	if mode&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(source.path)
+		if err != nil {
+			return nil, err
+		}
+		data = strings.NewReader(target)
+	} else {
+		file, err := os.Open(source.path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		tr.begin(source.name)
+		data = tr.reader(file)
+	}
+
+	compression := opts.compressionFor(source.name)
+	header.Flags |= deflateFlags(compression)
+	header.Method = compression.Method
+	if compression.Method == ZipZstd {
+		// Zstandard появился в версии 6.3 спецификации APPNOTE.
+		header.ReaderVersion = 63
+	}
+
+	entry.data = &spool{}
+	var out io.Writer = entry.data
+	var encrypted *aesWriter
+	if opts.Password != "" {
+		salt := make([]byte, aes256SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		encKey, macKey, verifier := aesKeys(opts.Password, salt, aes256KeyLen)
+		ctr, err := newWinzipCTR(encKey)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = &aesWriter{w: entry.data, ctr: ctr, mac: hmac.New(sha1.New, macKey)}
+		entry.prefix = append(salt, verifier...)
+		out = encrypted
+	}
+
+	compressor, err := newCompressor(out, compression)
+	if err != nil {
+		entry.close()
+		return nil, err
+	}
+	hash := crc32.NewIEEE()
+	size, err := io.Copy(compressor, io.TeeReader(data, hash))
+	if closeErr := compressor.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		entry.close()
+		return nil, err
+	}
+
+	header.UncompressedSize64 = uint64(size)
+	header.CRC32 = hash.Sum32()
+	if encrypted != nil {
+		// AE-2: CRC не хранится, его заменяет код аутентификации.
+		entry.suffix = encrypted.mac.Sum(nil)[:aesMACLen]
+		header.CRC32 = 0
+		header.Method = methodAES
+		header.Flags |= flagEncrypted
+		header.ReaderVersion = max(header.ReaderVersion, 51)
+		header.Extra = append(header.Extra, aesExtraField(compression.Method)...)
+	}
+	header.CompressedSize64 = uint64(len(entry.prefix)) + uint64(entry.data.size) + uint64(len(entry.suffix))
+	return entry, nil
+}
+
+func (e *preparedEntry) write(dst *zip.Writer) error {
+	if e.data == nil {
+		_, err := dst.CreateRaw(e.header)
+		return err
+	}
+
+	writer, err := dst.CreateRaw(e.header)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(e.prefix); err != nil {
+		return err
+	}
+	if err := e.data.copyTo(writer); err != nil {
+		return err
+	}
+	_, err = writer.Write(e.suffix)
+	return err
+}
+
+func (e *preparedEntry) close() {
+	if e.data != nil {
+		e.data.close()
+	}
+}
+
+// spool накапливает данные в памяти, а после spoolMemoryLimit байт
+// переносит их во временный файл.
+type spool struct {
+	buf  bytes.Buffer
+	file *os.File
+	size int64
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && s.buf.Len()+len(p) > spoolMemoryLimit {
+		file, err := os.CreateTemp("", "file-manager-zip-*")
+		if err != nil {
+			return 0, err
+		}
+		s.file = file
+		if _, err := s.buf.WriteTo(file); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+func (s *spool) copyTo(w io.Writer) error {
+	if s.file == nil {
+		_, err := w.Write(s.buf.Bytes())
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, s.file)
+	return err
+}
+
+func (s *spool) close() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+	}
+}
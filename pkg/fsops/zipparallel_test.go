@@ -0,0 +1,191 @@
+package fsops
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAddToZipWorkers(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "data")
+	if err := generateBenchFiles(source, 200, 4<<10); err != nil {
+		t.Fatal(err)
+	}
+	// Большой файл в начале сжимается дольше остальных, и при нескольких
+	// потоках последующие файлы готовы раньше него.
+	if err := os.WriteFile(filepath.Join(source, "0", "file-00000.txt"), []byte(strings.Repeat("большой файл ", 1<<17)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"store", "deflate", "zstd"} {
+		t.Run(name, func(t *testing.T) {
+			c, err := ParseCompression(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sequential := addWithWorkers(t, source, c, 1)
+			parallel := addWithWorkers(t, source, c, 8)
+
+			if len(parallel) != len(sequential) {
+				t.Fatalf("records = %d, want %d", len(parallel), len(sequential))
+			}
+			for i, want := range sequential {
+				if got := parallel[i]; got != want {
+					t.Errorf("record %d = %+v, want %+v", i, got, want)
+				}
+				if strings.HasSuffix(want.name, "/") {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(filepath.Dir(source), filepath.FromSlash(want.name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if crc := crc32.ChecksumIEEE(data); want.crc != crc || want.contentCRC != crc {
+					t.Errorf("%s: CRC не совпадает с исходным файлом", want.name)
+				}
+			}
+		})
+	}
+}
+
+// addedEntry описывает запись архива для сравнения результатов
+// AddToZip с разным числом потоков.
+type addedEntry struct {
+	name       string
+	crc        uint32
+	contentCRC uint32
+	size       uint64
+}
+
+// addWithWorkers добавляет source в новый архив и возвращает его записи
+// в порядке центрального каталога, проверяя, что он совпадает с порядком
+// данных в файле.
+func addWithWorkers(t *testing.T, source string, c Compression, workers int) []addedEntry {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "a.zip")
+	if err := CreateZip(archivePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddToZip(archivePath, []string{source}, AddOptions{Compression: &c, Workers: workers}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openZipReader(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var entries []addedEntry
+	var offset int64
+	for _, file := range r.File {
+		dataOffset, err := file.DataOffset()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataOffset < offset {
+			t.Errorf("workers=%d: %s записан раньше предыдущей записи", workers, file.Name)
+		}
+		offset = dataOffset
+
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := crc32.NewIEEE()
+		_, err = io.Copy(h, rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", file.Name, err)
+		}
+		entries = append(entries, addedEntry{file.Name, file.CRC32, h.Sum32(), file.UncompressedSize64})
+	}
+	return entries
+}
+
+// BenchmarkAddWorkers сравнивает скорость добавления файлов в архив при
+// разном числе потоков сжатия:
+//
+//	go test -run '^$' -bench AddWorkers ./pkg/fsops
+func BenchmarkAddWorkers(b *testing.B) {
+	const (
+		fileCount = 256
+		fileSize  = 64 << 10
+	)
+	source := filepath.Join(b.TempDir(), "data")
+	if err := generateBenchFiles(source, fileCount, fileSize); err != nil {
+		b.Fatal(err)
+	}
+
+	compressions := []string{"deflate", "zstd"}
+	for _, name := range compressions {
+		c, err := ParseCompression(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, workers := range benchWorkers() {
+			b.Run(fmt.Sprintf("%s/workers=%d", name, workers), func(b *testing.B) {
+				b.SetBytes(fileCount * fileSize)
+				archivePath := filepath.Join(b.TempDir(), "bench.zip")
+				opts := AddOptions{Compression: &c, Workers: workers}
+				for range b.N {
+					if err := CreateZip(archivePath); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := AddToZip(archivePath, []string{source}, opts); err != nil {
+						b.Fatal(err)
+					}
+					os.Remove(archivePath)
+				}
+			})
+		}
+	}
+}
+
+// benchWorkers возвращает 1, 2, 4, … и число процессоров.
+func benchWorkers() []int {
+	counts := []int{1}
+	for n := 2; n < runtime.NumCPU(); n *= 2 {
+		counts = append(counts, n)
+	}
+	if runtime.NumCPU() > 1 {
+		counts = append(counts, runtime.NumCPU())
+	}
+	return counts
+}
+
+// generateBenchFiles создаёт файлы из случайных слов: такие данные
+// сжимаются примерно как исходный код или журналы.
+func generateBenchFiles(dir string, count int, size int64) error {
+	words := strings.Fields("архив файл папка сжатие поток запись zip deflate error info debug 0 1 2 3 42 100 true false null")
+	rng := rand.New(rand.NewSource(1))
+
+	for i := range count {
+		sub := filepath.Join(dir, strconv.Itoa(i%32))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			return err
+		}
+
+		var b strings.Builder
+		for int64(b.Len()) < size {
+			b.WriteString(words[rng.Intn(len(words))])
+			if rng.Intn(12) == 0 {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		data := b.String()[:size]
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("file-%05d.txt", i)), []byte(data), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"slices"
+)
+
+const (
+	sigCentralDir    = 0x02014b50
+	sigEndOfDir      = 0x06054b50
+	sigZip64Locator  = 0x07064b50
+	sigZip64EndOfDir = 0x06064b50
+	endOfDirLen      = 22
+	zip64LocatorLen  = 20
+	zip64EndOfDirLen = 56
+)
+
+type EntryTestResult struct {
+	Name  string
+	Size  uint64
+	CRC32 uint32
+	Err   error
+}
+
+func (r EntryTestResult) OK() bool {
+	return r.Err == nil
+}
+
+type ArchiveTestReport struct {
+	Entries []EntryTestResult
+	// Problems описывает ошибки архива в целом: повреждённый или
+	// повторный центральный каталог, лишние данные, дубли имён.
+	Problems []string
+}
+
+func (r ArchiveTestReport) OK() bool {
+	if len(r.Problems) > 0 {
+		return false
+	}
+	for _, entry := range r.Entries {
+		if !entry.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// TestZip проверяет архив: структуру центрального каталога и каждую
+// запись, которая распаковывается целиком со сверкой CRC-32 и размеров.
+// Зашифрованные записи проверяются, только если задан password.
+// Ошибка возвращается, только если архив не удалось прочитать с диска;
+// повреждения архива попадают в отчёт.
+func TestZip(archivePath, password string) (ArchiveTestReport, error) {
+	var report ArchiveTestReport
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return report, wrap("проверка архива", archivePath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return report, wrap("проверка архива", archivePath, err)
+	}
+
+	ends, err := findEndsOfDir(file, info.Size())
+	if err != nil {
+		return report, wrap("проверка архива", archivePath, err)
+	}
+
+	zipReader, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		report.Problems = append(report.Problems, checkEndsOfDir(ends, info.Size())...)
+		report.Problems = append(report.Problems, fmt.Sprintf("не удалось прочитать центральный каталог: %v", err))
+		return report, nil
+	}
+	registerZstd(zipReader)
+	report.Problems = append(report.Problems, checkEndsOfDir(outsideEntries(ends, zipReader.File), info.Size())...)
+
+	seen := make(map[string]bool, len(zipReader.File))
+	for _, f := range zipReader.File {
+		if seen[f.Name] {
+			report.Problems = append(report.Problems, fmt.Sprintf("запись %q встречается несколько раз", f.Name))
+		}
+		seen[f.Name] = true
+
+		report.Entries = append(report.Entries, testEntry(f, password))
+	}
+	return report, nil
+}
+
+func testEntry(f *zip.File, password string) EntryTestResult {
+	result := EntryTestResult{Name: f.Name}
+
+	reader, err := openZipFile(f, password)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer reader.Close()
+
+	// archive/zip сам сверяет CRC-32 и размер в конце записи; здесь они
+	// считаются ещё раз, чтобы показать фактические значения в отчёте.
+	hash := crc32.NewIEEE()
+	n, err := io.Copy(hash, reader)
+	result.Size, result.CRC32 = uint64(n), hash.Sum32()
+
+	switch {
+	case err != nil:
+		result.Err = err
+	case result.Size != f.UncompressedSize64:
+		result.Err = fmt.Errorf("размер %d вместо заявленных %d", result.Size, f.UncompressedSize64)
+	case result.CRC32 != f.CRC32 && !f.FileInfo().IsDir() && !isAE2(f):
+		result.Err = fmt.Errorf("CRC-32 %08x вместо %08x: %w", result.CRC32, f.CRC32, zip.ErrChecksum)
+	}
+	return result
+}
+
+type endOfDir struct {
+	offset     int64
+	dirSize    int64
+	dirOffset  int64
+	commentLen int64
+	// dirEnd — где фактически заканчивается центральный каталог: сразу
+	// перед концом каталога или перед записями zip64.
+	dirEnd int64
+}
+
+// findEndsOfDir ищет в файле все записи «конец центрального каталога»,
+// которые действительно замыкают каталог. Одиночные совпадения сигнатуры
+// внутри сжатых данных отсеиваются проверкой начала каталога.
+func findEndsOfDir(r io.ReaderAt, size int64) ([]endOfDir, error) {
+	const chunkSize = 1 << 20
+	sig := []byte{'P', 'K', 5, 6}
+
+	var ends []endOfDir
+	buf := make([]byte, chunkSize+len(sig)-1)
+	for base := int64(0); base < size; base += chunkSize {
+		n, err := r.ReadAt(buf, base)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		for i := 0; ; {
+			j := bytes.Index(buf[i:n], sig)
+			if j < 0 {
+				break
+			}
+			offset := base + int64(i+j)
+			i += j + 1
+
+			if end, ok := readEndOfDir(r, offset, size); ok {
+				ends = append(ends, end)
+			}
+		}
+	}
+	return ends, nil
+}
+
+func readEndOfDir(r io.ReaderAt, offset, size int64) (endOfDir, bool) {
+	var raw [endOfDirLen]byte
+	if offset+endOfDirLen > size {
+		return endOfDir{}, false
+	}
+	if _, err := r.ReadAt(raw[:], offset); err != nil {
+		return endOfDir{}, false
+	}
+
+	end := endOfDir{
+		offset:     offset,
+		dirSize:    int64(binary.LittleEndian.Uint32(raw[12:])),
+		dirOffset:  int64(binary.LittleEndian.Uint32(raw[16:])),
+		commentLen: int64(binary.LittleEndian.Uint16(raw[20:])),
+	}
+	entries := uint64(binary.LittleEndian.Uint16(raw[10:]))
+	end.dirEnd = offset
+
+	// Записи zip64 пишутся и тогда, когда поля обычной записи не
+	// переполнены, например при потоковой записи архива.
+	if hasSignature(r, offset-zip64LocatorLen, sigZip64Locator) {
+		var raw64 [zip64EndOfDirLen]byte
+		end.dirEnd = offset - zip64LocatorLen - zip64EndOfDirLen
+		if end.dirEnd < 0 {
+			return endOfDir{}, false
+		}
+		if _, err := r.ReadAt(raw64[:], end.dirEnd); err != nil || binary.LittleEndian.Uint32(raw64[:]) != sigZip64EndOfDir {
+			return endOfDir{}, false
+		}
+		entries = binary.LittleEndian.Uint64(raw64[32:])
+		end.dirSize = int64(binary.LittleEndian.Uint64(raw64[40:]))
+		end.dirOffset = int64(binary.LittleEndian.Uint64(raw64[48:]))
+	}
+
+	if end.dirSize == 0 {
+		return end, entries == 0
+	}
+	return end, hasSignature(r, end.dirEnd-end.dirSize, sigCentralDir)
+}
+
+// outsideEntries отбрасывает концы каталога, которые лежат внутри данных
+// записей: так бывает у вложенного zip, сохранённого без сжатия.
+func outsideEntries(ends []endOfDir, files []*zip.File) []endOfDir {
+	type span struct{ start, end int64 }
+	spans := make([]span, 0, len(files))
+	for _, f := range files {
+		dataOffset, err := f.DataOffset()
+		if err != nil {
+			continue
+		}
+		spans = append(spans, span{start: dataOffset, end: dataOffset + int64(f.CompressedSize64)})
+	}
+
+	var result []endOfDir
+	for _, end := range ends {
+		inside := slices.ContainsFunc(spans, func(s span) bool {
+			return end.offset >= s.start && end.offset < s.end
+		})
+		if !inside {
+			result = append(result, end)
+		}
+	}
+	return result
+}
+
+func hasSignature(r io.ReaderAt, offset int64, sig uint32) bool {
+	var raw [4]byte
+	if offset < 0 {
+		return false
+	}
+	if _, err := r.ReadAt(raw[:], offset); err != nil {
+		return false
+	}
+	return binary.LittleEndian.Uint32(raw[:]) == sig
+}
+
+func checkEndsOfDir(ends []endOfDir, size int64) []string {
+	if len(ends) == 0 {
+		return []string{"не найден конец центрального каталога: архив обрезан или не является zip"}
+	}
+
+	var problems []string
+	if len(ends) > 1 {
+		problems = append(problems, fmt.Sprintf(
+			"найдено центральных каталогов: %d — к архиву дописывали данные без перезаписи, читатели увидят только последний", len(ends)))
+	}
+
+	last := ends[len(ends)-1]
+	if tail := last.offset + endOfDirLen + last.commentLen; tail < size {
+		problems = append(problems, fmt.Sprintf("после конца архива лишние данные: %d байт", size-tail))
+	} else if tail > size {
+		problems = append(problems, "комментарий архива обрезан")
+	}
+
+	if actual := last.dirEnd - last.dirSize; actual != last.dirOffset {
+		problems = append(problems, fmt.Sprintf(
+			"центральный каталог записан по смещению %d, а указан %d — смещения записей неверны", actual, last.dirOffset))
+	}
+	return problems
+}
+
+// isAE2 сообщает, что запись зашифрована AES в варианте AE-2, где CRC-32
+// не хранится.
+func isAE2(f *zip.File) bool {
+	extra, ok := parseAESExtra(f.Extra)
+	return f.Method == methodAES && ok && extra.version == 2
+}
@@ -0,0 +1,42 @@
+package fsops
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTestZip(t *testing.T) {
+	inner := buildZip(t, zip.Deflate, zipEntry{name: "inner.txt", data: []byte("вложенный файл")})
+	plain := buildZip(t, zip.Deflate, zipEntry{name: "a.txt", data: []byte("hello")})
+
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{"обычный архив", plain, true},
+		{"вложенный zip без сжатия", buildZip(t, zip.Store, zipEntry{name: "nested.zip", data: inner}, zipEntry{name: "b.txt", data: []byte("b")}), true},
+		{"вложенный zip со сжатием", buildZip(t, zip.Deflate, zipEntry{name: "nested.zip", data: inner}), true},
+		{"дописанный архив", append(append([]byte{}, plain...), plain...), false},
+		{"обрезанный архив", plain[:len(plain)-10], false},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".zip")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			report, err := TestZip(path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.OK() != tt.ok {
+				t.Errorf("OK() = %v, want %v; problems: %q", report.OK(), tt.ok, report.Problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrKeyExists = errors.New("ключ уже существует")
+
+// Get возвращает значение по пути p.
+func Get(root *Value, p Path) (*Value, error) {
+	cur := root
+	for i, seg := range p {
+		if seg.Append {
+			return nil, fmt.Errorf("%s: %w: [] допустимо только при записи", p[:i+1], ErrInvalidPath)
+		}
+		if err := expectKind(cur, p[:i], seg); err != nil {
+			return nil, err
+		}
+
+		if !seg.IsIndex {
+			next := cur.Field(seg.Key)
+			if next == nil {
+				return nil, fmt.Errorf("%s: %w", p[:i+1], ErrPathNotFound)
+			}
+			cur = next
+			continue
+		}
+		index, err := resolveIndex(p[:i+1], seg.Index, len(cur.Items), false)
+		if err != nil {
+			return nil, err
+		}
+		cur = cur.Items[index]
+	}
+	return cur, nil
+}
+
+// Delete удаляет поле объекта или элемент массива по пути p и
+// возвращает удалённое значение.
+func Delete(root *Value, p Path) (*Value, error) {
+	parent, last, err := parentOf(root, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !last.IsIndex {
+		i := slices.IndexFunc(parent.Fields, func(f *Field) bool { return f.Key == last.Key })
+		if i < 0 {
+			return nil, fmt.Errorf("%s: %w", p, ErrPathNotFound)
+		}
+		removed := parent.Fields[i].Value
+		parent.Fields = slices.Delete(parent.Fields, i, i+1)
+		return removed, nil
+	}
+
+	i, err := resolveIndex(p, last.Index, len(parent.Items), false)
+	if err != nil {
+		return nil, err
+	}
+	removed := parent.Items[i]
+	parent.Items = slices.Delete(parent.Items, i, i+1)
+	return removed, nil
+}
+
+// Rename меняет ключ поля по пути p на key, не меняя место поля в
+// объекте.
+func Rename(root *Value, p Path, key string) error {
+	parent, last, err := parentOf(root, p)
+	if err != nil {
+		return err
+	}
+	if last.IsIndex {
+		return fmt.Errorf("%s: %w: переименовать можно только поле объекта", p, ErrWrongType)
+	}
+	if last.Key == key {
+		return nil
+	}
+	if parent.Field(key) != nil {
+		return fmt.Errorf("%s: %w", Quote(key), ErrKeyExists)
+	}
+
+	for _, f := range parent.Fields {
+		if f.Key == last.Key {
+			f.Key = key
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", p, ErrPathNotFound)
+}
+
+// Append добавляет значения в конец существующего массива по пути p.
+func Append(root *Value, p Path, values ...*Value) error {
+	target, err := Get(root, p)
+	if err != nil {
+		return err
+	}
+	if target.Kind != Array {
+		return fmt.Errorf("%s: %w: ожидался array, а не %s", p.where(), ErrWrongType, target.Kind)
+	}
+	target.Items = append(target.Items, values...)
+	return nil
+}
+
+// parentOf находит контейнер, в котором лежит значение по пути p, и
+// проверяет, что последний шаг пути подходит к его типу.
+func parentOf(root *Value, p Path) (*Value, Segment, error) {
+	if len(p) == 0 {
+		return nil, Segment{}, fmt.Errorf("%w: путь не может быть пустым", ErrInvalidPath)
+	}
+	last := p[len(p)-1]
+	if last.Append {
+		return nil, Segment{}, fmt.Errorf("%s: %w: [] допустимо только при записи", p, ErrInvalidPath)
+	}
+
+	parent, err := Get(root, p[:len(p)-1])
+	if err != nil {
+		return nil, Segment{}, err
+	}
+	if err := expectKind(parent, p[:len(p)-1], last); err != nil {
+		return nil, Segment{}, err
+	}
+	return parent, last, nil
+}
+
+// expectKind проверяет, что к значению cur по пути at можно применить
+// шаг seg: ключ — к объекту, индекс — к массиву.
+func expectKind(cur *Value, at Path, seg Segment) error {
+	want := Object
+	if seg.IsIndex {
+		want = Array
+	}
+	if cur == nil || cur.Kind != want {
+		got := Null
+		if cur != nil {
+			got = cur.Kind
+		}
+		return fmt.Errorf("%s: %w: ожидался %s, а не %s", at.where(), ErrWrongType, want, got)
+	}
+	return nil
+}
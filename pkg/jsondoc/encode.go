@@ -0,0 +1,141 @@
+package jsondoc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Encode записывает значение в w. Пустой indent означает запись в одну
+// строку без пробелов, иначе каждый уровень вложенности сдвигается на
+// indent. Символы <, > и & не экранируются.
+func (v *Value) Encode(w io.Writer, indent string) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw, indent: indent}
+	e.value(v, 0)
+	return bw.Flush()
+}
+
+// ANSI-цвета элементов при выводе в терминал.
+const (
+	colorKey    = "\x1b[1;34m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[36m"
+	colorBool   = "\x1b[33m"
+	colorNull   = "\x1b[35m"
+	colorReset  = "\x1b[0m"
+)
+
+// EncodeColor работает как Encode, но выделяет ключи, строки, числа,
+// логические значения и null цветами ANSI для вывода в терминал.
+func (v *Value) EncodeColor(w io.Writer, indent string) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw, indent: indent, color: true}
+	e.value(v, 0)
+	return bw.Flush()
+}
+
+// Text возвращает запись значения в виде строки.
+func (v *Value) Text(indent string) string {
+	var buf bytes.Buffer
+	v.Encode(&buf, indent)
+	return buf.String()
+}
+
+func (v *Value) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	err := v.Encode(&buf, "")
+	return buf.Bytes(), err
+}
+
+type encoder struct {
+	w      *bufio.Writer
+	indent string
+	color  bool
+}
+
+// token записывает элемент, окрашивая его, если вывод цветной.
+func (e *encoder) token(color, s string) {
+	if !e.color {
+		e.w.WriteString(s)
+		return
+	}
+	e.w.WriteString(color)
+	e.w.WriteString(s)
+	e.w.WriteString(colorReset)
+}
+
+func (e *encoder) value(v *Value, depth int) {
+	if v == nil {
+		e.token(colorNull, "null")
+		return
+	}
+
+	switch v.Kind {
+	case Null:
+		e.token(colorNull, "null")
+	case Bool:
+		e.token(colorBool, strconv.FormatBool(v.Bool))
+	case Number:
+		e.token(colorNumber, v.Number)
+	case String:
+		e.token(colorString, v.source.quote(v.Str))
+	case Array:
+		if len(v.Items) == 0 {
+			e.w.WriteString("[]")
+			return
+		}
+		e.w.WriteByte('[')
+		for i, item := range v.Items {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			e.newline(depth + 1)
+			e.value(item, depth+1)
+		}
+		e.newline(depth)
+		e.w.WriteByte(']')
+	case Object:
+		if len(v.Fields) == 0 {
+			e.w.WriteString("{}")
+			return
+		}
+		e.w.WriteByte('{')
+		for i, f := range v.Fields {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			e.newline(depth + 1)
+			e.token(colorKey, f.source.quote(f.Key))
+			e.w.WriteByte(':')
+			if e.indent != "" {
+				e.w.WriteByte(' ')
+			}
+			e.value(f.Value, depth+1)
+		}
+		e.newline(depth)
+		e.w.WriteByte('}')
+	}
+}
+
+func (e *encoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.w.WriteByte('\n')
+	for range depth {
+		e.w.WriteString(e.indent)
+	}
+}
+
+// Quote записывает строку как строковый литерал JSON.
+func Quote(s string) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
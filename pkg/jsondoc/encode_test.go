@@ -0,0 +1,50 @@
+package jsondoc
+
+import "testing"
+
+func TestEncodeKeepsLiterals(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		edit  func(v *Value)
+		want  string
+	}{
+		{"экранирование \\u", `{"name":"caf\u00e9"}`, nil, `{"name":"caf\u00e9"}`},
+		{"экранирование в ключе", `{"caf\u00e9":1}`, nil, `{"caf\u00e9":1}`},
+		{"косая черта", `["a\/b","<&>"]`, nil, `["a\/b","<&>"]`},
+		{"запись числа", `[1.0e+2,-0]`, nil, `[1.0e+2,-0]`},
+		{
+			"изменённая строка записывается заново",
+			`{"a":"caf\u00e9","b":"\u0041"}`,
+			func(v *Value) { v.Field("a").Str = "tea" },
+			`{"a":"tea","b":"\u0041"}`,
+		},
+		{
+			"переименованный ключ записывается заново",
+			`{"a":1,"b":2}`,
+			func(v *Value) { v.Fields[0].Key = "c" },
+			`{"c":1,"b":2}`,
+		},
+		{
+			"новое значение",
+			`{"a":"\u00e9"}`,
+			func(v *Value) { v.SetField("b", NewString("é")) },
+			`{"a":"\u00e9","b":"é"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.edit != nil {
+				tt.edit(v)
+			}
+			if got := v.Text(""); got != tt.want {
+				t.Errorf("Text = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package jsondoc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+// Style описывает оформление файла: отступ уровня вложенности (пустой —
+// запись в одну строку) и перевод строки в конце файла.
+type Style struct {
+	Indent       string
+	FinalNewline bool
+}
+
+var DefaultStyle = Style{Indent: "  ", FinalNewline: true}
+
+// ReadFile читает и разбирает документ из файла path и определяет
+// оформление файла, чтобы записать его изменённым в том же виде.
+func ReadFile(path string) (*Value, Style, error) {
+	data, err := fsops.ReadFile(path)
+	if err != nil {
+		return nil, Style{}, err
+	}
+	v, err := Parse(data)
+	if err != nil {
+		return nil, Style{}, &fsops.Error{Op: "разбор JSON", Path: path, Err: err}
+	}
+	return v, DetectStyle(data), nil
+}
+
+// DetectStyle берёт отступ из первой строки документа, которая начинается
+// с пробелов или табуляции. Документ без таких строк считается записанным
+// в одну строку.
+func DetectStyle(data []byte) Style {
+	style := Style{FinalNewline: bytes.HasSuffix(data, []byte("\n"))}
+	for _, line := range bytes.Split(data, []byte("\n"))[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if n := len(line) - len(trimmed); n > 0 && len(bytes.TrimSpace(trimmed)) > 0 {
+			style.Indent = string(line[:n])
+			break
+		}
+	}
+	return style
+}
+
+// WriteFile атомарно записывает документ в файл path: при ошибке прежнее
+// содержимое файла остаётся нетронутым.
+func WriteFile(path string, v *Value, style Style) error {
+	err := fsops.WriteAtomic(path, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		if err := v.Encode(bw, style.Indent); err != nil {
+			return err
+		}
+		if style.FinalNewline {
+			bw.WriteByte('\n')
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return &fsops.Error{Op: "запись JSON в файл", Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package jsondoc
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// filterExpr — условие фильтра [?...], проверяемое для каждого дочернего
+// значения.
+type filterExpr interface {
+	test(n Match, root *Value) bool
+}
+
+type orExpr []filterExpr
+
+func (e orExpr) test(n Match, root *Value) bool {
+	for _, sub := range e {
+		if sub.test(n, root) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []filterExpr
+
+func (e andExpr) test(n Match, root *Value) bool {
+	for _, sub := range e {
+		if !sub.test(n, root) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e notExpr) test(n Match, root *Value) bool {
+	return !e.expr.test(n, root)
+}
+
+// existsExpr истинно, если путь нашёл хотя бы одно значение.
+type existsExpr struct {
+	path pathOperand
+}
+
+func (e existsExpr) test(n Match, root *Value) bool {
+	return len(e.path.nodes(n, root)) > 0
+}
+
+type compareExpr struct {
+	op          string
+	left, right operand
+	re          *regexp.Regexp
+}
+
+func (e compareExpr) test(n Match, root *Value) bool {
+	a, aok := e.left.value(n, root)
+	b, bok := e.right.value(n, root)
+
+	switch e.op {
+	case "==":
+		return equalOrNothing(a, aok, b, bok)
+	case "!=":
+		return !equalOrNothing(a, aok, b, bok)
+	case "=~":
+		return aok && a.Kind == String && e.re.MatchString(a.Str)
+	}
+
+	if !aok || !bok {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return less(a, b)
+	case "<=":
+		return less(a, b) || equal(a, b)
+	case ">":
+		return less(b, a)
+	case ">=":
+		return less(b, a) || equal(a, b)
+	}
+	return false
+}
+
+// equalOrNothing сравнивает значения; отсутствующие значения равны
+// только друг другу.
+func equalOrNothing(a *Value, aok bool, b *Value, bok bool) bool {
+	if !aok || !bok {
+		return !aok && !bok
+	}
+	return equal(a, b)
+}
+
+func equal(a, b *Value) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case Null:
+		return true
+	case Bool:
+		return a.Bool == b.Bool
+	case Number:
+		return compareNumbers(a.Number, b.Number) == 0
+	case String:
+		return a.Str == b.Str
+	case Array:
+		if len(a.Items) != len(b.Items) {
+			return false
+		}
+		for i := range a.Items {
+			if !equal(a.Items[i], b.Items[i]) {
+				return false
+			}
+		}
+		return true
+	case Object:
+		if len(a.Fields) != len(b.Fields) {
+			return false
+		}
+		for _, f := range a.Fields {
+			other := b.Field(f.Key)
+			if other == nil || !equal(f.Value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// less упорядочивает только числа с числами и строки со строками.
+func less(a, b *Value) bool {
+	switch {
+	case a.Kind == Number && b.Kind == Number:
+		return compareNumbers(a.Number, b.Number) < 0
+	case a.Kind == String && b.Kind == String:
+		return a.Str < b.Str
+	}
+	return false
+}
+
+// compareNumbers сравнивает записи чисел без потери точности больших
+// целых, которую дал бы float64.
+func compareNumbers(a, b string) int {
+	x, _, errA := big.ParseFloat(a, 10, 256, big.ToNearestEven)
+	y, _, errB := big.ParseFloat(b, 10, 256, big.ToNearestEven)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return x.Cmp(y)
+}
+
+type operand interface {
+	value(n Match, root *Value) (*Value, bool)
+}
+
+type literalOperand struct {
+	v *Value
+}
+
+func (o literalOperand) value(Match, *Value) (*Value, bool) {
+	return o.v, true
+}
+
+// pathOperand — путь от текущего значения (@) или от корня ($).
+type pathOperand struct {
+	absolute bool
+	steps    []step
+}
+
+func (o pathOperand) nodes(n Match, root *Value) []Match {
+	if o.absolute {
+		return selectSteps(o.steps, Match{Value: root}, root)
+	}
+	return selectSteps(o.steps, n, root)
+}
+
+// value возвращает значение, только если путь нашёл ровно одно.
+func (o pathOperand) value(n Match, root *Value) (*Value, bool) {
+	nodes := o.nodes(n, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].Value, true
+}
+
+func (p *queryParser) orExpr() (filterExpr, error) {
+	var or orExpr
+	for {
+		and, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, and)
+		p.skipSpace()
+		if !p.eat("||") {
+			break
+		}
+		p.skipSpace()
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *queryParser) andExpr() (filterExpr, error) {
+	var and andExpr
+	for {
+		expr, err := p.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+		p.skipSpace()
+		if !p.eat("&&") {
+			break
+		}
+		p.skipSpace()
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *queryParser) unaryExpr() (filterExpr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && !strings.HasPrefix(p.s[p.pos:], "!=") {
+		p.pos++
+		expr, err := p.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	if p.eat("(") {
+		expr, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.eat(")") {
+			return nil, p.errorf("ожидалась )")
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *queryParser) comparison() (filterExpr, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+
+	var op string
+	for _, candidate := range compareOps {
+		if p.eat(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		path, ok := left.(pathOperand)
+		if !ok {
+			return nil, p.errorf("ожидался оператор сравнения")
+		}
+		return existsExpr{path: path}, nil
+	}
+
+	p.skipSpace()
+	start := p.pos
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	expr := compareExpr{op: op, left: left, right: right}
+	if op == "=~" {
+		lit, ok := right.(literalOperand)
+		if !ok || lit.v.Kind != String {
+			p.pos = start
+			return nil, p.errorf("справа от =~ ожидалось регулярное выражение в кавычках")
+		}
+		if expr.re, err = regexp.Compile(lit.v.Str); err != nil {
+			p.pos = start
+			return nil, p.errorf("неверное регулярное выражение: %v", err)
+		}
+	}
+	return expr, nil
+}
+
+func (p *queryParser) operand() (operand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		steps, err := p.steps()
+		if err != nil {
+			return nil, err
+		}
+		return pathOperand{absolute: c == '$', steps: steps}, nil
+	case c == '\'' || c == '"':
+		s, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{v: NewString(s)}, nil
+	case c == '-' || isDigit(c):
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("+-.eE0123456789", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		v, err := NewNumber(p.s[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("%v", err)
+		}
+		return literalOperand{v: v}, nil
+	}
+
+	for word, v := range map[string]*Value{"true": NewBool(true), "false": NewBool(false), "null": NewNull()} {
+		if p.eat(word) {
+			return literalOperand{v: v}, nil
+		}
+	}
+	return nil, p.errorf("ожидался путь @ или $, строка, число, true, false или null")
+}
@@ -0,0 +1,40 @@
+package jsondoc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxIndent — наибольший отступ в пробелах, который принимает ParseIndent.
+const maxIndent = 16
+
+// SortKeys упорядочивает поля всех объектов документа по ключам.
+func SortKeys(v *Value) {
+	switch v.Kind {
+	case Object:
+		slices.SortStableFunc(v.Fields, func(a, b *Field) int { return strings.Compare(a.Key, b.Key) })
+		for _, f := range v.Fields {
+			SortKeys(f.Value)
+		}
+	case Array:
+		for _, item := range v.Items {
+			SortKeys(item)
+		}
+	}
+}
+
+// ParseIndent переводит запись отступа в строку: число пробелов от 1 до
+// maxIndent или "tab" для табуляции.
+func ParseIndent(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "tab" || s == `\t` {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > maxIndent {
+		return "", fmt.Errorf("неверный отступ %q, ожидается число пробелов от 1 до %d или tab", s, maxIndent)
+	}
+	return strings.Repeat(" ", n), nil
+}
@@ -0,0 +1,169 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SyntaxError сообщает место ошибки разбора: строку и столбец
+// считаются с единицы, столбец — в символах, а не байтах.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Offset int64
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("строка %d, столбец %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse разбирает документ JSON. После значения допускаются только
+// пробельные символы. Повторный ключ в объекте считается ошибкой: иначе
+// при перезаписи файла одно из значений молча потерялось бы.
+func Parse(data []byte) (*Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := parseValue(dec, data)
+	if err == nil {
+		rest := tokenStart(data, dec.InputOffset())
+		if _, tokErr := dec.Token(); tokErr != io.EOF {
+			err = syntaxError(data, rest, "лишние данные после значения")
+		}
+	}
+	if err != nil {
+		return nil, positioned(data, dec, err)
+	}
+	return v, nil
+}
+
+func parseValue(dec *json.Decoder, data []byte) (*Value, error) {
+	start := tokenStart(data, dec.InputOffset())
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case nil:
+		return NewNull(), nil
+	case bool:
+		return NewBool(t), nil
+	case json.Number:
+		return &Value{Kind: Number, Number: t.String()}, nil
+	case string:
+		v := NewString(t)
+		v.source = literal{text: string(data[start:dec.InputOffset()]), value: t}
+		return v, nil
+	case json.Delim:
+		switch t {
+		case '[':
+			v := NewArray()
+			for dec.More() {
+				item, err := parseValue(dec, data)
+				if err != nil {
+					return nil, err
+				}
+				v.Items = append(v.Items, item)
+			}
+			_, err := dec.Token()
+			return v, err
+		case '{':
+			v := NewObject()
+			keys := make(map[string]bool)
+			for dec.More() {
+				keyStart := tokenStart(data, dec.InputOffset())
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				keySource := literal{text: string(data[keyStart:dec.InputOffset()]), value: key}
+				if keys[key] {
+					return nil, syntaxError(data, keyStart, "повторяется ключ "+Quote(key))
+				}
+				keys[key] = true
+
+				item, err := parseValue(dec, data)
+				if err != nil {
+					return nil, err
+				}
+				v.Fields = append(v.Fields, &Field{Key: key, Value: item, source: keySource})
+			}
+			_, err := dec.Token()
+			return v, err
+		}
+	}
+	return nil, fmt.Errorf("неожиданный элемент %v", tok)
+}
+
+// syntaxMessages переводит сообщения об ошибках encoding/json.
+var syntaxMessages = strings.NewReplacer(
+	"invalid character ", "недопустимый символ ",
+	" after array element", " после элемента массива",
+	" after object key:value pair", " после пары ключ:значение",
+	" after object key", " после ключа объекта",
+	" after top-level value", " после значения",
+	" looking for beginning of value", " вместо начала значения",
+	" looking for beginning of object key string", " вместо ключа объекта в кавычках",
+	" in string literal", " в строке",
+	"invalid escape sequence ", "неверное экранирование ",
+	" in string", " в строке",
+	"object member name must be a string", "имя поля объекта должно быть строкой",
+	" in string escape code", " в экранировании строки",
+	" in numeric literal", " в числе",
+	" in \\u hexadecimal character escape", " в экранировании \\u",
+	" in literal ", " в литерале ",
+	"(expecting ", "(ожидался ",
+	"unexpected end of JSON input", "неожиданный конец данных",
+)
+
+// positioned переводит ошибки encoding/json в SyntaxError с номером
+// строки и столбца.
+func positioned(data []byte, dec *json.Decoder, err error) error {
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return err
+	}
+
+	var jsonErr *json.SyntaxError
+	switch {
+	case errors.As(err, &jsonErr):
+		msg := syntaxMessages.Replace(jsonErr.Error())
+		// Offset указывает на байт после ошибочного символа, а при
+		// обрыве данных — на последний прочитанный, поэтому место обрыва
+		// отмечается в самом конце.
+		offset := max(jsonErr.Offset-1, 0)
+		if strings.HasPrefix(jsonErr.Error(), "unexpected end") {
+			offset = int64(len(data))
+		}
+		return syntaxError(data, offset, msg)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return syntaxError(data, int64(len(data)), "неожиданный конец данных")
+	default:
+		return syntaxError(data, dec.InputOffset(), err.Error())
+	}
+}
+
+// tokenStart пропускает от offset пробелы и разделители , и : до начала
+// следующего элемента.
+func tokenStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func syntaxError(data []byte, offset int64, msg string) *SyntaxError {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	column := len([]rune(string(before[lineStart:]))) + 1
+	return &SyntaxError{Line: line, Column: column, Offset: offset, Msg: msg}
+}
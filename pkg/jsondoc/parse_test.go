@@ -0,0 +1,49 @@
+package jsondoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+		msg    string
+	}{
+		{"обрыв массива", "[1,2,", 1, 6, "неожиданный конец"},
+		{"обрыв с переводом строки", "[1,2,\n", 2, 1, "неожиданный конец"},
+		{"обрыв объекта", "{\"a\":1,\n  \"b\":", 2, 7, "неожиданный конец"},
+		{"недопустимый символ", "[1,\n x]", 2, 2, "недопустимый символ"},
+		{"повтор ключа", "{\"a\":1,\"a\":2}", 1, 8, `повторяется ключ "a"`},
+		{"повтор ключа с отступами", "{\n  \"a\": 1,\n  \"b\": {\"x\": 1},\n  \"a\": 2\n}", 4, 3, `повторяется ключ "a"`},
+		{"повтор во вложенном объекте", "[{\"k\":1, \"k\":1}]", 1, 10, `повторяется ключ "k"`},
+		{"лишние данные", "{} []", 1, 4, "лишние данные"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("err = %v, want SyntaxError", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column || !strings.Contains(syntaxErr.Msg, tt.msg) {
+				t.Errorf("err = %v, want строка %d, столбец %d: %s", err, tt.line, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseKeepsKeyOrder(t *testing.T) {
+	v, err := Parse([]byte(`{"b":1,"a":{"y":2,"x":3},"A":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := v.Text(""), `{"b":1,"a":{"y":2,"x":3},"A":4}`; got != want {
+		t.Errorf("Text = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segment — шаг пути: ключ объекта или индекс массива. Отрицательный
+// индекс отсчитывается с конца, Append — запись "[]", новый элемент в
+// конце массива.
+type Segment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+	Append  bool
+}
+
+// Path — путь к значению внутри документа. В записи ключи разделяются
+// точкой, индексы пишутся в скобках: server.ports[0]. Ключ с точкой или
+// скобками записывается строкой JSON в скобках: ["a.b"]. Пустой путь —
+// корень документа.
+type Path []Segment
+
+func ParsePath(s string) (Path, error) {
+	var p Path
+	s = strings.TrimSpace(s)
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			end, seg, err := parseBracket(s, i)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, seg)
+			i = end
+		case s[i] == '.' && i > 0 || i == 0:
+			if s[i] == '.' {
+				i++
+			}
+			end := i
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("%w %q: пустой ключ в позиции %d", ErrInvalidPath, s, i+1)
+			}
+			p = append(p, Segment{Key: s[i:end]})
+			i = end
+		default:
+			return nil, fmt.Errorf("%w %q: ожидалась точка или скобка в позиции %d", ErrInvalidPath, s, i+1)
+		}
+	}
+	return p, nil
+}
+
+// parseBracket разбирает [N], [] или ["ключ"], начиная со скобки в
+// позиции start, и возвращает позицию после закрывающей скобки.
+func parseBracket(s string, start int) (int, Segment, error) {
+	i := start + 1
+	if i < len(s) && s[i] == '"' {
+		end := i + 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		var key string
+		if end >= len(s) || json.Unmarshal([]byte(s[i:end+1]), &key) != nil {
+			return 0, Segment{}, fmt.Errorf("%w %q: неверная строка ключа в позиции %d", ErrInvalidPath, s, i+1)
+		}
+		if end+1 >= len(s) || s[end+1] != ']' {
+			return 0, Segment{}, fmt.Errorf("%w %q: нет закрывающей скобки", ErrInvalidPath, s)
+		}
+		return end + 2, Segment{Key: key}, nil
+	}
+
+	end := strings.IndexByte(s[i:], ']')
+	if end < 0 {
+		return 0, Segment{}, fmt.Errorf("%w %q: нет закрывающей скобки", ErrInvalidPath, s)
+	}
+	text := strings.TrimSpace(s[i : i+end])
+	if text == "" {
+		return i + end + 1, Segment{IsIndex: true, Append: true}, nil
+	}
+	index, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, Segment{}, fmt.Errorf("%w %q: неверный индекс %q", ErrInvalidPath, s, text)
+	}
+	return i + end + 1, Segment{Index: index, IsIndex: true}, nil
+}
+
+func (p Path) String() string {
+	var b strings.Builder
+	for _, seg := range p {
+		switch {
+		case seg.Append:
+			b.WriteString("[]")
+		case seg.IsIndex:
+			fmt.Fprintf(&b, "[%d]", seg.Index)
+		case plainKey(seg.Key):
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(seg.Key)
+		default:
+			b.WriteString("[" + Quote(seg.Key) + "]")
+		}
+	}
+	return b.String()
+}
+
+// where возвращает запись пути для сообщений об ошибках.
+func (p Path) where() string {
+	if len(p) == 0 {
+		return "корень документа"
+	}
+	return p.String()
+}
+
+// plainKey сообщает, можно ли записать ключ без кавычек.
+func plainKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, ".[]\"") && strings.TrimSpace(key) == key
+}
+
+// Set записывает value по пути p и возвращает новый корень документа.
+// Недостающие объекты и массивы создаются по виду следующего шага пути;
+// индекс, равный длине массива, добавляет элемент в конец.
+func Set(root *Value, p Path, value *Value) (*Value, error) {
+	return set(root, p, 0, value)
+}
+
+func set(cur *Value, p Path, i int, value *Value) (*Value, error) {
+	if i == len(p) {
+		return value, nil
+	}
+
+	seg := p[i]
+	if !seg.IsIndex {
+		if cur == nil {
+			cur = NewObject()
+		}
+		if cur.Kind != Object {
+			return nil, fmt.Errorf("%s: %w: ожидался object, а не %s", p[:i].where(), ErrWrongType, cur.Kind)
+		}
+		child, err := set(cur.Field(seg.Key), p, i+1, value)
+		if err != nil {
+			return nil, err
+		}
+		cur.SetField(seg.Key, child)
+		return cur, nil
+	}
+
+	if cur == nil {
+		cur = NewArray()
+	}
+	if cur.Kind != Array {
+		return nil, fmt.Errorf("%s: %w: ожидался array, а не %s", p[:i].where(), ErrWrongType, cur.Kind)
+	}
+	index := len(cur.Items)
+	if !seg.Append {
+		var err error
+		if index, err = resolveIndex(p[:i+1], seg.Index, len(cur.Items), true); err != nil {
+			return nil, err
+		}
+	}
+
+	var old *Value
+	if index < len(cur.Items) {
+		old = cur.Items[index]
+	}
+	child, err := set(old, p, i+1, value)
+	if err != nil {
+		return nil, err
+	}
+	if index == len(cur.Items) {
+		cur.Items = append(cur.Items, child)
+	} else {
+		cur.Items[index] = child
+	}
+	return cur, nil
+}
+
+// resolveIndex переводит отрицательный индекс в отсчёт от конца массива
+// длины length и проверяет границы; allowEnd разрешает индекс length —
+// место для нового элемента.
+func resolveIndex(at Path, index, length int, allowEnd bool) (int, error) {
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index > length || index == length && !allowEnd {
+		return 0, fmt.Errorf("%s: %w (длина %d)", at.where(), ErrIndexRange, length)
+	}
+	return index, nil
+}
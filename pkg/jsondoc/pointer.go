@@ -0,0 +1,58 @@
+package jsondoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Pointer записывает путь как JSON Pointer (RFC 6901): /store/book/0.
+// Корень документа — пустая строка.
+func (p Path) Pointer() string {
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteByte('/')
+		if seg.IsIndex {
+			b.WriteString(strconv.Itoa(seg.Index))
+		} else {
+			b.WriteString(pointerEscaper.Replace(seg.Key))
+		}
+	}
+	return b.String()
+}
+
+// GetPointer возвращает значение по JSON Pointer. Шаг пути считается
+// индексом, если текущее значение — массив.
+func GetPointer(root *Value, pointer string) (*Value, error) {
+	if pointer == "" {
+		return root, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w %q: JSON Pointer должен начинаться с /", ErrInvalidPath, pointer)
+	}
+
+	cur := root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = pointerUnescaper.Replace(token)
+		switch cur.Kind {
+		case Object:
+			next := cur.Field(token)
+			if next == nil {
+				return nil, fmt.Errorf("%s: %w", pointer, ErrPathNotFound)
+			}
+			cur = next
+		case Array:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(cur.Items) || token != strconv.Itoa(i) {
+				return nil, fmt.Errorf("%s: %w", pointer, ErrPathNotFound)
+			}
+			cur = cur.Items[i]
+		default:
+			return nil, fmt.Errorf("%s: %w", pointer, ErrPathNotFound)
+		}
+	}
+	return cur, nil
+}
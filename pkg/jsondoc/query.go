@@ -0,0 +1,447 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidQuery = errors.New("неверное выражение JSONPath")
+
+// Query — скомпилированное выражение JSONPath (RFC 9535): имена и
+// индексы ($.a.b[0], $['a']), подстановка (*), рекурсивный спуск (..),
+// срезы ([1:5:2]), объединения ([0,'a']) и фильтры
+// ([?@.price < 10 && @.tags]). Выражение без $ считается отсчитанным от
+// корня, как в jq: .a.b и a.b равны $.a.b.
+type Query struct {
+	text  string
+	steps []step
+}
+
+// Match — найденное значение и путь к нему в документе.
+type Match struct {
+	Path  Path
+	Value *Value
+}
+
+// step — сегмент выражения: селекторы применяются к каждому текущему
+// узлу, а при рекурсивном спуске — ещё и ко всем его потомкам.
+type step struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selector interface {
+	apply(n Match, root *Value, out []Match) []Match
+}
+
+func CompileQuery(expr string) (*Query, error) {
+	text := strings.TrimSpace(expr)
+	switch {
+	case text == "":
+		text = "$"
+	case strings.HasPrefix(text, "$"):
+	case strings.HasPrefix(text, ".") || strings.HasPrefix(text, "["):
+		text = "$" + text
+	default:
+		text = "$." + text
+	}
+
+	p := &queryParser{s: text, pos: 1}
+	steps, err := p.steps()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("неожиданный символ %q", p.s[p.pos])
+	}
+	return &Query{text: text, steps: steps}, nil
+}
+
+func (q *Query) String() string {
+	return q.text
+}
+
+// Select возвращает совпадения в порядке документа.
+func (q *Query) Select(root *Value) []Match {
+	return selectSteps(q.steps, Match{Value: root}, root)
+}
+
+func selectSteps(steps []step, start Match, root *Value) []Match {
+	nodes := []Match{start}
+	for _, st := range steps {
+		var next []Match
+		for _, n := range nodes {
+			if st.descendant {
+				walk(n, func(d Match) {
+					for _, sel := range st.selectors {
+						next = sel.apply(d, root, next)
+					}
+				})
+				continue
+			}
+			for _, sel := range st.selectors {
+				next = sel.apply(n, root, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// walk обходит узел и всех его потомков в порядке документа.
+func walk(n Match, fn func(Match)) {
+	fn(n)
+	forEachChild(n, func(child Match) { walk(child, fn) })
+}
+
+func forEachChild(n Match, fn func(Match)) {
+	switch n.Value.Kind {
+	case Array:
+		for i, item := range n.Value.Items {
+			fn(Match{Path: n.Path.child(Segment{Index: i, IsIndex: true}), Value: item})
+		}
+	case Object:
+		for _, f := range n.Value.Fields {
+			fn(Match{Path: n.Path.child(Segment{Key: f.Key}), Value: f.Value})
+		}
+	}
+}
+
+// child возвращает копию пути с добавленным шагом, чтобы пути соседних
+// узлов не делили один массив.
+func (p Path) child(seg Segment) Path {
+	return append(slices.Clip(p), seg)
+}
+
+// JSONPath записывает путь в нормализованном виде выражения JSONPath:
+// $.store.book[0], ключи с особыми символами — $["a.b"].
+func (p Path) JSONPath() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, seg := range p {
+		switch {
+		case seg.IsIndex:
+			fmt.Fprintf(&b, "[%d]", seg.Index)
+		case plainKey(seg.Key):
+			b.WriteString("." + seg.Key)
+		default:
+			b.WriteString("[" + Quote(seg.Key) + "]")
+		}
+	}
+	return b.String()
+}
+
+type nameSelector string
+
+func (s nameSelector) apply(n Match, _ *Value, out []Match) []Match {
+	if v := n.Value.Field(string(s)); v != nil {
+		out = append(out, Match{Path: n.Path.child(Segment{Key: string(s)}), Value: v})
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(n Match, _ *Value, out []Match) []Match {
+	forEachChild(n, func(child Match) { out = append(out, child) })
+	return out
+}
+
+type indexSelector int
+
+func (s indexSelector) apply(n Match, _ *Value, out []Match) []Match {
+	if n.Value.Kind != Array {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(n.Value.Items)
+	}
+	if i < 0 || i >= len(n.Value.Items) {
+		return out
+	}
+	return append(out, Match{Path: n.Path.child(Segment{Index: i, IsIndex: true}), Value: n.Value.Items[i]})
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(n Match, _ *Value, out []Match) []Match {
+	if n.Value.Kind != Array || s.step == 0 {
+		return out
+	}
+
+	length := len(n.Value.Items)
+	normalize := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		if *i < 0 {
+			return *i + length
+		}
+		return *i
+	}
+	add := func(i int) {
+		out = append(out, Match{Path: n.Path.child(Segment{Index: i, IsIndex: true}), Value: n.Value.Items[i]})
+	}
+
+	if s.step > 0 {
+		lower := clamp(normalize(s.start, 0), 0, length)
+		upper := clamp(normalize(s.end, length), 0, length)
+		for i := lower; i < upper; i += s.step {
+			add(i)
+		}
+		return out
+	}
+	upper := clamp(normalize(s.start, length-1), -1, length-1)
+	lower := clamp(normalize(s.end, -length-1), -1, length-1)
+	for i := upper; i > lower; i += s.step {
+		add(i)
+	}
+	return out
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+type filterSelector struct {
+	expr filterExpr
+}
+
+func (s filterSelector) apply(n Match, root *Value, out []Match) []Match {
+	forEachChild(n, func(child Match) {
+		if s.expr.test(child, root) {
+			out = append(out, child)
+		}
+	})
+	return out
+}
+
+type queryParser struct {
+	s   string
+	pos int
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: позиция %d: %s", ErrInvalidQuery, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *queryParser) eat(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// steps разбирает сегменты до первого символа, с которого сегмент
+// начаться не может: конец выражения, оператор или скобка фильтра.
+func (p *queryParser) steps() ([]step, error) {
+	var steps []step
+	for {
+		var st step
+		switch {
+		case p.eat(".."):
+			st.descendant = true
+			if p.peek() == '[' {
+				sels, err := p.bracket()
+				if err != nil {
+					return nil, err
+				}
+				st.selectors = sels
+				break
+			}
+			sel, err := p.dotSelector()
+			if err != nil {
+				return nil, err
+			}
+			st.selectors = []selector{sel}
+		case p.eat("."):
+			sel, err := p.dotSelector()
+			if err != nil {
+				return nil, err
+			}
+			st.selectors = []selector{sel}
+		case p.peek() == '[':
+			sels, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			st.selectors = sels
+		default:
+			return steps, nil
+		}
+		steps = append(steps, st)
+	}
+}
+
+// dotSelector разбирает запись после точки: * или имя без кавычек.
+func (p *queryParser) dotSelector() (selector, error) {
+	if p.eat("*") {
+		return wildcardSelector{}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(".[]()=!<>&|,'\" \t\r\n~", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.errorf("ожидалось имя ключа или *")
+	}
+	return nameSelector(p.s[start:p.pos]), nil
+}
+
+func (p *queryParser) bracket() ([]selector, error) {
+	p.eat("[")
+	var sels []selector
+	for {
+		p.skipSpace()
+		sel, err := p.bracketSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipSpace()
+		if p.eat(",") {
+			continue
+		}
+		if !p.eat("]") {
+			return nil, p.errorf("ожидалась , или ]")
+		}
+		return sels, nil
+	}
+}
+
+func (p *queryParser) bracketSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '\'' || c == '"':
+		name, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(name), nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: expr}, nil
+	}
+
+	start, hasStart, err := p.optionalInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eat(":") {
+		if !hasStart {
+			return nil, p.errorf("ожидался индекс, срез, имя в кавычках, * или фильтр ?")
+		}
+		return indexSelector(start), nil
+	}
+
+	sel := sliceSelector{step: 1}
+	if hasStart {
+		sel.start = &start
+	}
+	p.skipSpace()
+	end, hasEnd, err := p.optionalInt()
+	if err != nil {
+		return nil, err
+	}
+	if hasEnd {
+		sel.end = &end
+	}
+	p.skipSpace()
+	if p.eat(":") {
+		p.skipSpace()
+		step, hasStep, err := p.optionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			sel.step = step
+		}
+	}
+	return sel, nil
+}
+
+func (p *queryParser) optionalInt() (int, bool, error) {
+	start := p.pos
+	p.eat("-")
+	for p.pos < len(p.s) && isDigit(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	text := p.s[start:p.pos]
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("неверное число %q", text)
+	}
+	return n, true, nil
+}
+
+// stringLiteral разбирает строку в одинарных или двойных кавычках с
+// экранированием как в JSON.
+func (p *queryParser) stringLiteral() (string, error) {
+	quote := p.s[p.pos]
+	start := p.pos
+	var b strings.Builder
+	b.WriteByte('"')
+	for p.pos++; p.pos < len(p.s) && p.s[p.pos] != quote; p.pos++ {
+		switch c := p.s[p.pos]; {
+		case c == '\\' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '\'':
+			b.WriteByte('\'')
+			p.pos++
+		case c == '\\' && p.pos+1 < len(p.s):
+			b.WriteByte(c)
+			b.WriteByte(p.s[p.pos+1])
+			p.pos++
+		case c == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if p.pos >= len(p.s) {
+		p.pos = start
+		return "", p.errorf("незакрытая строка")
+	}
+	p.pos++
+	b.WriteByte('"')
+
+	var s string
+	if err := json.Unmarshal([]byte(b.String()), &s); err != nil {
+		p.pos = start
+		return "", p.errorf("неверная строка: %v", err)
+	}
+	return s, nil
+}
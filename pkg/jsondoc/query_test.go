@@ -0,0 +1,129 @@
+package jsondoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const storeDoc = `{
+  "store": {
+    "book": [
+      {"title": "Сказки", "price": 8.95, "tags": ["дети"]},
+      {"title": "Война и мир", "price": 22.99, "isbn": "0-553-21311-3"},
+      {"title": "Моби Дик", "price": 8.99, "isbn": "0-395-19395-8"},
+      {"title": "Властелин колец", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 399}
+  },
+  "limit": 10,
+  "a.b": 1
+}`
+
+// selectText возвращает найденные значения одной строкой через пробел.
+func selectText(t *testing.T, doc, expr string) string {
+	t.Helper()
+	root, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := CompileQuery(expr)
+	if err != nil {
+		t.Fatalf("CompileQuery(%q): %v", expr, err)
+	}
+	var values []string
+	for _, m := range q.Select(root) {
+		values = append(values, m.Value.Text(""))
+	}
+	return strings.Join(values, " ")
+}
+
+func TestQuerySlices(t *testing.T) {
+	const doc = `[0, 1, 2, 3, 4, 5]`
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$[1:3]", "1 2"},
+		{"$[:2]", "0 1"},
+		{"$[4:]", "4 5"},
+		{"$[-2:]", "4 5"},
+		{"$[:-4]", "0 1"},
+		{"$[::2]", "0 2 4"},
+		{"$[1::2]", "1 3 5"},
+		{"$[::-1]", "5 4 3 2 1 0"},
+		{"$[5:1:-2]", "5 3"},
+		{"$[-1:-3:-1]", "5 4"},
+		{"$[3:1]", ""},
+		{"$[10:20]", ""},
+		{"$[-10:2]", "0 1"},
+		{"$[::0]", ""},
+		{"$[0,2,-1]", "0 2 5"},
+		{"$[1:3,5]", "1 2 5"},
+	}
+
+	for _, tt := range tests {
+		if got := selectText(t, doc, tt.expr); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$.store.book[?@.price < 10].title", `"Сказки" "Моби Дик"`},
+		{"$.store.book[?@.price == 22.99].title", `"Война и мир" "Властелин колец"`},
+		{"$.store.book[?@.price >= 22.99 && @.isbn].title", `"Война и мир"`},
+		{"$.store.book[?@.price < 9 || @.price > 20].title", `"Сказки" "Война и мир" "Моби Дик" "Властелин колец"`},
+		{"$.store.book[?@.isbn].title", `"Война и мир" "Моби Дик"`},
+		{"$.store.book[?!@.isbn].title", `"Сказки" "Властелин колец"`},
+		{"$.store.book[?@.title =~ '^В'].title", `"Война и мир" "Властелин колец"`},
+		{"$.store.book[?@.price < $.limit].title", `"Сказки" "Моби Дик"`},
+		{"$.store.book[?@.tags[0] == 'дети'].title", `"Сказки"`},
+		{"$.store.book[?@.price != 22.99].price", `8.95 8.99`},
+		{"$..[?@.color == 'red'].price", `399`},
+		{"$.store.book[?@.missing == 1]", ``},
+		{"$..price", `8.95 22.99 8.99 22.99 399`},
+		{"$['a.b']", `1`},
+		{".limit", `10`},
+	}
+
+	for _, tt := range tests {
+		if got := selectText(t, storeDoc, tt.expr); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestQueryPaths(t *testing.T) {
+	root, err := Parse([]byte(storeDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := CompileQuery("$.store.book[-1:]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := q.Select(root)
+	if len(matches) != 1 {
+		t.Fatalf("matches = %d, want 1", len(matches))
+	}
+	if got, want := matches[0].Path.JSONPath(), "$.store.book[3]"; got != want {
+		t.Errorf("JSONPath = %s, want %s", got, want)
+	}
+	if got, want := matches[0].Path.Pointer(), "/store/book/3"; got != want {
+		t.Errorf("Pointer = %s, want %s", got, want)
+	}
+}
+
+func TestCompileQueryErrors(t *testing.T) {
+	for _, expr := range []string{"$[", "$[1:2", "$.", "$[?@.a ==]", "$[?@.a =~ '[']", "$['a]", "$[?(@.a]"} {
+		if _, err := CompileQuery(expr); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("CompileQuery(%q) err = %v, want %v", expr, err, ErrInvalidQuery)
+		}
+	}
+}
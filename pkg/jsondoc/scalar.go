@@ -0,0 +1,67 @@
+package jsondoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Типы, которые можно указать при вводе значения вместо определения
+// по тексту.
+const (
+	TypeAuto   = "auto"
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeNull   = "null"
+	TypeJSON   = "json"
+)
+
+var Types = []string{TypeAuto, TypeString, TypeNumber, TypeBool, TypeNull, TypeJSON}
+
+// Infer определяет тип значения по тексту: null, true, false и числа
+// становятся значениями своих типов, текст в фигурных или квадратных
+// скобках либо в кавычках разбирается как JSON, остальное — строка.
+func Infer(text string) *Value {
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "null":
+		return NewNull()
+	case trimmed == "true" || trimmed == "false":
+		return NewBool(trimmed == "true")
+	case isNumber(trimmed):
+		return &Value{Kind: Number, Number: trimmed}
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, `"`):
+		if v, err := Parse([]byte(trimmed)); err == nil {
+			return v
+		}
+	}
+	return NewString(text)
+}
+
+// ParseScalar разбирает текст как значение типа typ из Types; пустой
+// тип равен TypeAuto.
+func ParseScalar(text, typ string) (*Value, error) {
+	trimmed := strings.TrimSpace(text)
+	switch strings.ToLower(strings.TrimSpace(typ)) {
+	case "", TypeAuto:
+		return Infer(text), nil
+	case TypeString, "str", "s":
+		return NewString(text), nil
+	case TypeNumber, "num", "n":
+		return NewNumber(trimmed)
+	case TypeBool, "boolean", "b":
+		switch strings.ToLower(trimmed) {
+		case "true", "1", "yes", "да":
+			return NewBool(true), nil
+		case "false", "0", "no", "нет":
+			return NewBool(false), nil
+		}
+		return nil, fmt.Errorf("%q не является логическим значением, ожидается true или false", text)
+	case TypeNull:
+		return NewNull(), nil
+	case TypeJSON, "j":
+		return Parse([]byte(trimmed))
+	default:
+		return nil, fmt.Errorf("неизвестный тип %q, ожидается один из: %s", typ, strings.Join(Types, ", "))
+	}
+}
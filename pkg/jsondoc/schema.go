@@ -0,0 +1,527 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidSchema  = errors.New("неверная схема")
+	ErrSchemaMismatch = errors.New("документ не соответствует схеме")
+)
+
+// maxRefChain ограничивает число переходов по $ref без спуска внутрь
+// значения, чтобы схема вида {"$ref": "#"} не зацикливала проверку.
+const maxRefChain = 32
+
+// Violation — нарушение схемы: JSON Pointer значения в документе,
+// JSON Pointer ключевого слова в схеме и описание.
+type Violation struct {
+	Pointer string
+	Keyword string
+	Message string
+}
+
+func (v Violation) Error() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "(корень)"
+	}
+	return pointer + ": " + v.Message
+}
+
+// Schema — проверенная схема JSON Schema (draft 2020-12). Поддерживаются
+// ключевые слова type, enum, const, числовые minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum и multipleOf, строковые minLength,
+// maxLength и pattern, для массивов items, prefixItems, contains,
+// minItems, maxItems и uniqueItems, для объектов properties,
+// patternProperties, additionalProperties, propertyNames, required,
+// minProperties и maxProperties, а также allOf, anyOf, oneOf, not,
+// if/then/else и $ref на части той же схемы ("#/$defs/..." или
+// "#якорь" из $anchor). Остальные ключевые слова, в том числе format,
+// не проверяются.
+type Schema struct {
+	root      *Value
+	anchors   map[string]*Value
+	locations map[*Value]string
+	patterns  map[string]*regexp.Regexp
+}
+
+var schemaTypes = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// Ключевые слова, значения которых — подсхема, объект подсхем или
+// массив подсхем.
+var (
+	schemaKeywords      = []string{"additionalProperties", "items", "contains", "not", "if", "then", "else", "propertyNames"}
+	schemaMapKeywords   = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+	schemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+func CompileSchema(root *Value) (*Schema, error) {
+	if root.Kind != Object && root.Kind != Bool {
+		return nil, fmt.Errorf("%w: схема должна быть объектом или true/false", ErrInvalidSchema)
+	}
+
+	s := &Schema{
+		root:      root,
+		anchors:   make(map[string]*Value),
+		locations: make(map[*Value]string),
+		patterns:  make(map[string]*regexp.Regexp),
+	}
+	var order []*Value
+	s.collect(root, "", &order)
+	// check может добавить в order подсхемы, на которые ведут $ref.
+	for i := 0; i < len(order); i++ {
+		if err := s.check(order[i], &order); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// collect запоминает расположение подсхем и якоря $anchor, а сами
+// подсхемы добавляет в order в порядке обхода.
+func (s *Schema) collect(sch *Value, at string, order *[]*Value) {
+	if sch.Kind != Object {
+		return
+	}
+	s.locations[sch] = at
+	*order = append(*order, sch)
+	if anchor := sch.Field("$anchor"); anchor != nil && anchor.Kind == String {
+		s.anchors[anchor.Str] = sch
+	}
+
+	for _, f := range sch.Fields {
+		sub := at + "/" + pointerEscaper.Replace(f.Key)
+		switch {
+		case slices.Contains(schemaKeywords, f.Key):
+			s.collect(f.Value, sub, order)
+		case slices.Contains(schemaMapKeywords, f.Key):
+			for _, item := range f.Value.Fields {
+				s.collect(item.Value, sub+"/"+pointerEscaper.Replace(item.Key), order)
+			}
+		case slices.Contains(schemaArrayKeywords, f.Key):
+			for i, item := range f.Value.Items {
+				s.collect(item, sub+"/"+strconv.Itoa(i), order)
+			}
+		}
+	}
+}
+
+// check проверяет ключевые слова одной подсхемы, значения которых
+// нужно разобрать заранее: $ref, type, enum и регулярные выражения.
+// Подсхемы, на которые ссылается $ref из необычных мест схемы,
+// добавляются в order.
+func (s *Schema) check(sch *Value, order *[]*Value) error {
+	at := s.locations[sch]
+	if ref := sch.Field("$ref"); ref != nil {
+		if ref.Kind != String {
+			return fmt.Errorf("%w: %s/$ref: ожидалась строка", ErrInvalidSchema, at)
+		}
+		target, err := s.resolve(ref.Str)
+		if err != nil {
+			return fmt.Errorf("%w: %s/$ref: %w", ErrInvalidSchema, at, err)
+		}
+		if _, ok := s.locations[target]; !ok && target.Kind == Object {
+			s.collect(target, unescapeFragment(strings.TrimPrefix(ref.Str, "#")), order)
+		}
+	}
+	if enum := sch.Field("enum"); enum != nil && enum.Kind != Array {
+		return fmt.Errorf("%w: %s/enum: ожидался массив", ErrInvalidSchema, at)
+	}
+
+	if typ := sch.Field("type"); typ != nil {
+		names := []*Value{typ}
+		if typ.Kind == Array {
+			names = typ.Items
+		}
+		for _, name := range names {
+			if name.Kind != String || !slices.Contains(schemaTypes, name.Str) {
+				return fmt.Errorf("%w: %s/type: неизвестный тип %s", ErrInvalidSchema, at, name.Text(""))
+			}
+		}
+	}
+
+	var patterns []string
+	if pattern := sch.Field("pattern"); pattern != nil && pattern.Kind == String {
+		patterns = append(patterns, pattern.Str)
+	}
+	if props := sch.Field("patternProperties"); props != nil {
+		for _, f := range props.Fields {
+			patterns = append(patterns, f.Key)
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %s: регулярное выражение %q: %w", ErrInvalidSchema, at, pattern, err)
+		}
+		s.patterns[pattern] = re
+	}
+	return nil
+}
+
+// resolve находит подсхему по ссылке внутри этой же схемы.
+func (s *Schema) resolve(ref string) (*Value, error) {
+	fragment, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("ссылки на другие документы не поддерживаются: %s", ref)
+	}
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		target, err := GetPointer(s.root, unescapeFragment(fragment))
+		if err != nil {
+			return nil, err
+		}
+		if target.Kind != Object && target.Kind != Bool {
+			return nil, fmt.Errorf("%s указывает не на схему", ref)
+		}
+		return target, nil
+	}
+	if target, ok := s.anchors[fragment]; ok {
+		return target, nil
+	}
+	return nil, fmt.Errorf("якорь %s не найден", ref)
+}
+
+// unescapeFragment раскрывает %XX во фрагменте URI.
+func unescapeFragment(fragment string) string {
+	var b strings.Builder
+	for i := 0; i < len(fragment); i++ {
+		if fragment[i] == '%' && i+2 < len(fragment) {
+			if n, err := strconv.ParseUint(fragment[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(fragment[i])
+	}
+	return b.String()
+}
+
+// Validate проверяет документ и возвращает все нарушения в порядке
+// ключевых слов схемы; пустой список означает, что документ подходит.
+func (s *Schema) Validate(doc *Value) []Violation {
+	return s.validate(s.root, "", doc, nil, 0)
+}
+
+func (s *Schema) valid(sch *Value, at string, inst *Value, path Path, refs int) bool {
+	return len(s.validate(sch, at, inst, path, refs)) == 0
+}
+
+func (s *Schema) validate(sch *Value, at string, inst *Value, path Path, refs int) []Violation {
+	var out []Violation
+	fail := func(keyword, format string, args ...any) {
+		out = append(out, Violation{
+			Pointer: path.Pointer(),
+			Keyword: at + "/" + keyword,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if sch.Kind == Bool {
+		if !sch.Bool {
+			out = append(out, Violation{Pointer: path.Pointer(), Keyword: at, Message: "схема false не допускает никаких значений"})
+		}
+		return out
+	}
+	if sch.Kind != Object {
+		return nil
+	}
+
+	for _, f := range sch.Fields {
+		kw, arg := f.Key, f.Value
+		sub := at + "/" + pointerEscaper.Replace(kw)
+
+		switch kw {
+		case "$ref":
+			if refs >= maxRefChain {
+				fail(kw, "слишком длинная цепочка $ref без спуска внутрь значения")
+				continue
+			}
+			target, err := s.resolve(arg.Str)
+			if err != nil {
+				fail(kw, "%v", err)
+				continue
+			}
+			out = append(out, s.validate(target, s.locations[target], inst, path, refs+1)...)
+
+		case "type":
+			names := []*Value{arg}
+			if arg.Kind == Array {
+				names = arg.Items
+			}
+			if !slices.ContainsFunc(names, func(name *Value) bool { return hasType(inst, name.Str) }) {
+				list := make([]string, len(names))
+				for i, name := range names {
+					list[i] = name.Str
+				}
+				fail(kw, "ожидался тип %s, а не %s", strings.Join(list, " или "), inst.Kind)
+			}
+
+		case "enum":
+			if !slices.ContainsFunc(arg.Items, func(v *Value) bool { return equal(inst, v) }) {
+				fail(kw, "значение %s не входит в список допустимых %s", inst.Text(""), arg.Text(""))
+			}
+		case "const":
+			if !equal(inst, arg) {
+				fail(kw, "значение %s не равно %s", inst.Text(""), arg.Text(""))
+			}
+
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			if inst.Kind != Number || arg.Kind != Number {
+				continue
+			}
+			x, limit := rat(inst.Number), rat(arg.Number)
+			if x == nil || limit == nil {
+				continue
+			}
+			switch cmp := x.Cmp(limit); {
+			case kw == "minimum" && cmp < 0:
+				fail(kw, "значение %s меньше минимума %s", inst.Number, arg.Number)
+			case kw == "maximum" && cmp > 0:
+				fail(kw, "значение %s больше максимума %s", inst.Number, arg.Number)
+			case kw == "exclusiveMinimum" && cmp <= 0:
+				fail(kw, "значение %s должно быть больше %s", inst.Number, arg.Number)
+			case kw == "exclusiveMaximum" && cmp >= 0:
+				fail(kw, "значение %s должно быть меньше %s", inst.Number, arg.Number)
+			case kw == "multipleOf" && limit.Sign() > 0 && !new(big.Rat).Quo(x, limit).IsInt():
+				fail(kw, "значение %s не кратно %s", inst.Number, arg.Number)
+			}
+
+		case "minLength", "maxLength":
+			n, ok := intValue(arg)
+			if inst.Kind != String || !ok {
+				continue
+			}
+			length := utf8.RuneCountInString(inst.Str)
+			if kw == "minLength" && length < n {
+				fail(kw, "длина строки %d меньше %d", length, n)
+			} else if kw == "maxLength" && length > n {
+				fail(kw, "длина строки %d больше %d", length, n)
+			}
+		case "pattern":
+			if re := s.patterns[arg.Str]; inst.Kind == String && re != nil && !re.MatchString(inst.Str) {
+				fail(kw, "строка %s не подходит под шаблон %s", Quote(inst.Str), Quote(arg.Str))
+			}
+
+		case "minItems", "maxItems":
+			n, ok := intValue(arg)
+			if inst.Kind != Array || !ok {
+				continue
+			}
+			if kw == "minItems" && len(inst.Items) < n {
+				fail(kw, "в массиве %d элементов, а нужно не меньше %d", len(inst.Items), n)
+			} else if kw == "maxItems" && len(inst.Items) > n {
+				fail(kw, "в массиве %d элементов, а нужно не больше %d", len(inst.Items), n)
+			}
+		case "uniqueItems":
+			if inst.Kind != Array || arg.Kind != Bool || !arg.Bool {
+				continue
+			}
+			for i := range inst.Items {
+				for j := i + 1; j < len(inst.Items); j++ {
+					if equal(inst.Items[i], inst.Items[j]) {
+						fail(kw, "элементы %d и %d совпадают", i, j)
+					}
+				}
+			}
+		case "prefixItems":
+			if inst.Kind != Array {
+				continue
+			}
+			for i, itemSchema := range arg.Items {
+				if i < len(inst.Items) {
+					out = append(out, s.validate(itemSchema, sub+"/"+strconv.Itoa(i), inst.Items[i], path.child(Segment{Index: i, IsIndex: true}), 0)...)
+				}
+			}
+		case "items":
+			if inst.Kind != Array {
+				continue
+			}
+			start := 0
+			if prefix := sch.Field("prefixItems"); prefix != nil {
+				start = len(prefix.Items)
+			}
+			for i := start; i < len(inst.Items); i++ {
+				out = append(out, s.validate(arg, sub, inst.Items[i], path.child(Segment{Index: i, IsIndex: true}), 0)...)
+			}
+		case "contains":
+			if inst.Kind != Array {
+				continue
+			}
+			count := 0
+			for i, item := range inst.Items {
+				if s.valid(arg, sub, item, path.child(Segment{Index: i, IsIndex: true}), 0) {
+					count++
+				}
+			}
+			minCount, maxCount := 1, -1
+			if n, ok := intValue(sch.Field("minContains")); ok {
+				minCount = n
+			}
+			if n, ok := intValue(sch.Field("maxContains")); ok {
+				maxCount = n
+			}
+			if count < minCount {
+				fail(kw, "подходящих под contains элементов %d, а нужно не меньше %d", count, minCount)
+			} else if maxCount >= 0 && count > maxCount {
+				fail(kw, "подходящих под contains элементов %d, а нужно не больше %d", count, maxCount)
+			}
+
+		case "required":
+			if inst.Kind != Object {
+				continue
+			}
+			for _, name := range arg.Items {
+				if name.Kind == String && inst.Field(name.Str) == nil {
+					fail(kw, "нет обязательного поля %s", Quote(name.Str))
+				}
+			}
+		case "properties":
+			if inst.Kind != Object {
+				continue
+			}
+			for _, prop := range arg.Fields {
+				if v := inst.Field(prop.Key); v != nil {
+					out = append(out, s.validate(prop.Value, sub+"/"+pointerEscaper.Replace(prop.Key), v, path.child(Segment{Key: prop.Key}), 0)...)
+				}
+			}
+		case "patternProperties":
+			if inst.Kind != Object {
+				continue
+			}
+			for _, field := range inst.Fields {
+				for _, prop := range arg.Fields {
+					if s.patterns[prop.Key].MatchString(field.Key) {
+						out = append(out, s.validate(prop.Value, sub+"/"+pointerEscaper.Replace(prop.Key), field.Value, path.child(Segment{Key: field.Key}), 0)...)
+					}
+				}
+			}
+		case "additionalProperties":
+			if inst.Kind != Object {
+				continue
+			}
+			for _, field := range inst.Fields {
+				if s.declared(sch, field.Key) {
+					continue
+				}
+				fieldPath := path.child(Segment{Key: field.Key})
+				if arg.Kind == Bool && !arg.Bool {
+					out = append(out, Violation{Pointer: fieldPath.Pointer(), Keyword: sub, Message: fmt.Sprintf("поле %s не разрешено схемой", Quote(field.Key))})
+					continue
+				}
+				out = append(out, s.validate(arg, sub, field.Value, fieldPath, 0)...)
+			}
+		case "propertyNames":
+			if inst.Kind != Object {
+				continue
+			}
+			for _, field := range inst.Fields {
+				for _, v := range s.validate(arg, sub, NewString(field.Key), path.child(Segment{Key: field.Key}), 0) {
+					v.Message = "имя поля: " + v.Message
+					out = append(out, v)
+				}
+			}
+		case "minProperties", "maxProperties":
+			n, ok := intValue(arg)
+			if inst.Kind != Object || !ok {
+				continue
+			}
+			if kw == "minProperties" && len(inst.Fields) < n {
+				fail(kw, "в объекте %d полей, а нужно не меньше %d", len(inst.Fields), n)
+			} else if kw == "maxProperties" && len(inst.Fields) > n {
+				fail(kw, "в объекте %d полей, а нужно не больше %d", len(inst.Fields), n)
+			}
+
+		case "allOf":
+			for i, item := range arg.Items {
+				out = append(out, s.validate(item, sub+"/"+strconv.Itoa(i), inst, path, refs)...)
+			}
+		case "anyOf":
+			if !slices.ContainsFunc(arg.Items, func(item *Value) bool { return s.valid(item, sub, inst, path, refs) }) {
+				fail(kw, "значение не подходит ни под одну из %d схем anyOf", len(arg.Items))
+			}
+		case "oneOf":
+			count := 0
+			for i, item := range arg.Items {
+				if s.valid(item, sub+"/"+strconv.Itoa(i), inst, path, refs) {
+					count++
+				}
+			}
+			if count != 1 {
+				fail(kw, "значение подходит под %d из %d схем oneOf, а должно ровно под одну", count, len(arg.Items))
+			}
+		case "not":
+			if s.valid(arg, sub, inst, path, refs) {
+				fail(kw, "значение не должно подходить под схему not")
+			}
+		case "if":
+			branch := "else"
+			if s.valid(arg, sub, inst, path, refs) {
+				branch = "then"
+			}
+			if next := sch.Field(branch); next != nil {
+				out = append(out, s.validate(next, at+"/"+branch, inst, path, refs)...)
+			}
+		}
+	}
+	return out
+}
+
+// declared сообщает, описано ли поле в properties или patternProperties
+// схемы: такие поля не проверяются по additionalProperties.
+func (s *Schema) declared(sch *Value, key string) bool {
+	if sch.Field("properties").Field(key) != nil {
+		return true
+	}
+	if props := sch.Field("patternProperties"); props != nil {
+		for _, prop := range props.Fields {
+			if s.patterns[prop.Key].MatchString(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasType(v *Value, name string) bool {
+	switch name {
+	case "integer":
+		if v.Kind != Number {
+			return false
+		}
+		x := rat(v.Number)
+		return x != nil && x.IsInt()
+	case "boolean":
+		return v.Kind == Bool
+	default:
+		return v.Kind.String() == name
+	}
+}
+
+func rat(number string) *big.Rat {
+	x, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil
+	}
+	return x
+}
+
+// intValue возвращает неотрицательное целое значение ключевого слова.
+func intValue(v *Value) (int, bool) {
+	if v == nil || v.Kind != Number {
+		return 0, false
+	}
+	x := rat(v.Number)
+	if x == nil || !x.IsInt() || x.Sign() < 0 || !x.Num().IsInt64() {
+		return 0, false
+	}
+	return int(x.Num().Int64()), true
+}
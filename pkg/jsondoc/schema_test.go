@@ -0,0 +1,110 @@
+package jsondoc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	const person = `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "age": {"type": "integer", "minimum": 0, "maximum": 150},
+    "email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+    "tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false
+}`
+
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		// want — нарушения в виде "указатель в документе|указатель в схеме".
+		want []string
+	}{
+		{"корректный документ", person, `{"name": "Аня", "age": 30, "tags": ["a", "b"]}`, nil},
+		{"нет обязательного поля", person, `{"name": "Аня"}`, []string{"|/required"}},
+		{"неверный тип", person, `{"name": "Аня", "age": "30"}`, []string{"/age|/properties/age/type"}},
+		{"дробное вместо целого", person, `{"name": "Аня", "age": 30.5}`, []string{"/age|/properties/age/type"}},
+		{"целое с нулевой дробью", person, `{"name": "Аня", "age": 30.0}`, nil},
+		{"границы числа", person, `{"name": "Аня", "age": 151}`, []string{"/age|/properties/age/maximum"}},
+		{"длина строки в символах", person, `{"name": "", "age": 1}`, []string{"/name|/properties/name/minLength"}},
+		{"шаблон", person, `{"name": "Аня", "age": 1, "email": "нет"}`, []string{"/email|/properties/email/pattern"}},
+		{
+			"элементы массива",
+			person,
+			`{"name": "Аня", "age": 1, "tags": ["a", 1, "a", "b"]}`,
+			[]string{"/tags/1|/properties/tags/items/type", "/tags|/properties/tags/uniqueItems", "/tags|/properties/tags/maxItems"},
+		},
+		{"лишнее поле", person, `{"name": "Аня", "age": 1, "x": 1}`, []string{"/x|/additionalProperties"}},
+		{"корень другого типа", person, `[]`, []string{"|/type"}},
+		{
+			"ссылки на $defs",
+			`{"$defs": {"pos": {"type": "number", "exclusiveMinimum": 0}}, "type": "array", "items": {"$ref": "#/$defs/pos"}}`,
+			`[1, 0, -1]`,
+			[]string{"/1|/$defs/pos/exclusiveMinimum", "/2|/$defs/pos/exclusiveMinimum"},
+		},
+		{
+			"oneOf",
+			`{"oneOf": [{"type": "integer"}, {"minimum": 10}]}`,
+			`20`,
+			[]string{"|/oneOf"},
+		},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "null"}]}`, `null`, nil},
+		{"enum и const", `{"enum": [1, "a", {"k": [true]}]}`, `{"k": [true]}`, nil},
+		{"if then else", `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"type": "number"}}`, `true`, []string{"|/else/type"}},
+		{"multipleOf с дробями", `{"multipleOf": 0.01}`, `19.99`, nil},
+		{"схема false", `{"properties": {"x": false}}`, `{"x": 1}`, []string{"/x|/properties/x"}},
+		{"имя поля с косой чертой", `{"properties": {"a/b": {"type": "string"}}}`, `{"a/b": 1}`, []string{"/a~1b|/properties/a~1b/type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaDoc, err := Parse([]byte(tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			schema, err := CompileSchema(schemaDoc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, v := range schema.Validate(doc) {
+				got = append(got, v.Pointer+"|"+v.Keyword)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		`[]`,
+		`{"type": "integers"}`,
+		`{"pattern": "["}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "#missing"}`,
+		`{"$ref": "other.json#/a"}`,
+		`{"enum": 1}`,
+		`{"patternProperties": {"(": {}}}`,
+	} {
+		doc, err := Parse([]byte(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CompileSchema(doc); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("CompileSchema(%s) err = %v, want %v", schema, err, ErrInvalidSchema)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package jsondoc хранит документ JSON в виде дерева, в котором объекты
+// сохраняют порядок ключей, а числа и строки — исходную запись. Так
+// документ можно прочитать, изменить и записать обратно без перестановки
+// ключей и потери точности, как это бывает с map[string]any.
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPathNotFound = errors.New("путь не найден")
+	ErrWrongType    = errors.New("значение другого типа")
+	ErrIndexRange   = errors.New("индекс за пределами массива")
+	ErrInvalidPath  = errors.New("неверный путь")
+)
+
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "boolean"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Array:
+		return "array"
+	case Object:
+		return "object"
+	default:
+		return fmt.Sprintf("kind-%d", int(k))
+	}
+}
+
+// Value — значение JSON. Используется поле, соответствующее Kind:
+// Bool, Number (запись числа как в исходном тексте), Str, Items или
+// Fields в порядке добавления.
+type Value struct {
+	Kind   Kind
+	Bool   bool
+	Number string
+	Str    string
+	Items  []*Value
+	Fields []*Field
+
+	// source — запись строки в исходном тексте: пока Str не меняли,
+	// строка записывается с теми же экранированиями, что и была.
+	source literal
+}
+
+type Field struct {
+	Key   string
+	Value *Value
+
+	source literal
+}
+
+// literal — исходная запись строки text в кавычках и строка value,
+// которую она задаёт.
+type literal struct {
+	text, value string
+}
+
+// quote возвращает исходную запись s, если s не изменилась после
+// разбора, иначе записывает s заново.
+func (l literal) quote(s string) string {
+	if l.text != "" && l.value == s {
+		return l.text
+	}
+	return Quote(s)
+}
+
+func NewNull() *Value                 { return &Value{Kind: Null} }
+func NewBool(b bool) *Value           { return &Value{Kind: Bool, Bool: b} }
+func NewString(s string) *Value       { return &Value{Kind: String, Str: s} }
+func NewArray(items ...*Value) *Value { return &Value{Kind: Array, Items: items} }
+func NewObject() *Value               { return &Value{Kind: Object} }
+
+// NewNumber проверяет, что text — число в синтаксисе JSON.
+func NewNumber(text string) (*Value, error) {
+	if !isNumber(text) {
+		return nil, fmt.Errorf("%q не является числом JSON", text)
+	}
+	return &Value{Kind: Number, Number: text}, nil
+}
+
+// Field возвращает значение поля key объекта или nil.
+func (v *Value) Field(key string) *Value {
+	if v == nil || v.Kind != Object {
+		return nil
+	}
+	for _, f := range v.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// SetField заменяет значение поля key, оставляя его на прежнем месте,
+// или добавляет поле в конец объекта.
+func (v *Value) SetField(key string, value *Value) {
+	for _, f := range v.Fields {
+		if f.Key == key {
+			f.Value = value
+			return
+		}
+	}
+	v.Fields = append(v.Fields, &Field{Key: key, Value: value})
+}
+
+// isNumber проверяет грамматику числа JSON:
+// -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func isNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	default:
+		return false
+	}
+
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i >= len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i >= len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,258 @@
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+// Корзина устроена по спецификации freedesktop.org Trash: удалённые файлы
+// лежат в Trash/files, а рядом в Trash/info хранятся .trashinfo с исходным
+// путём и временем удаления. Поэтому файлы, удалённые здесь, видны в
+// корзине рабочего стола, и наоборот.
+
+const dateLayout = "2006-01-02T15:04:05"
+
+var ErrNotInTrash = errors.New("файл не найден в корзине")
+
+type Item struct {
+	Name         string
+	OriginalPath string
+	DeletedAt    time.Time
+}
+
+// Dir возвращает $XDG_DATA_HOME/Trash или ~/.local/share/Trash.
+func Dir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// Put перемещает файл или папку path в корзину.
+func Put(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return &fsops.Error{Op: "удаление в корзину", Path: path, Err: fmt.Errorf("%w: %w", fsops.ErrNotFound, err)}
+	}
+
+	trashDir, err := Dir()
+	if err != nil {
+		return err
+	}
+	filesDir, infoDir := filepath.Join(trashDir, "files"), filepath.Join(trashDir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return err
+	}
+
+	name, infoFile, err := reserveName(infoDir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(infoFile, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: path}).EscapedPath(), time.Now().Format(dateLayout))
+	if closeErr := infoFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fsops.Move(path, filepath.Join(filesDir, name), fsops.TransferOptions{})
+	}
+	if err != nil {
+		os.Remove(filepath.Join(infoDir, name+".trashinfo"))
+		return err
+	}
+	return nil
+}
+
+// reserveName создаёт .trashinfo с уникальным именем, чтобы одновременно
+// удаляемые файлы с одинаковыми именами не перезаписали друг друга.
+func reserveName(infoDir, base string) (string, *os.File, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+
+		file, err := os.OpenFile(filepath.Join(infoDir, name+".trashinfo"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return name, file, err
+	}
+}
+
+// List возвращает содержимое корзины, начиная с недавно удалённых.
+func List() ([]Item, error) {
+	trashDir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	infoDir := filepath.Join(trashDir, "info")
+	entries, err := os.ReadDir(infoDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+		if !ok {
+			continue
+		}
+
+		item, err := readInfo(filepath.Join(infoDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		item.Name = name
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+func readInfo(path string) (Item, error) {
+	var item Item
+
+	file, err := os.Open(path)
+	if err != nil {
+		return item, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "Path":
+			if item.OriginalPath, err = url.PathUnescape(value); err != nil {
+				return item, err
+			}
+		case "DeletionDate":
+			if item.DeletedAt, err = time.ParseInLocation(dateLayout, value, time.Local); err != nil {
+				return item, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return item, err
+	}
+	if item.OriginalPath == "" {
+		return item, fmt.Errorf("%s: нет поля Path", path)
+	}
+	return item, nil
+}
+
+// Restore возвращает файл name из корзины на исходное место, при
+// необходимости создавая недостающие папки. Если на месте уже есть файл,
+// решение принимает opts.OnConflict; если он выбрал пропуск, Restore
+// возвращает false, а файл остаётся в корзине.
+func Restore(name string, opts fsops.TransferOptions) (bool, error) {
+	// name — имя файла в корзине, а не путь: иначе ../ вывело бы за её
+	// пределы.
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return false, &fsops.Error{Op: "восстановление", Path: name, Err: fsops.ErrInvalidName}
+	}
+
+	trashDir, err := Dir()
+	if err != nil {
+		return false, err
+	}
+
+	infoPath := filepath.Join(trashDir, "info", name+".trashinfo")
+	item, err := readInfo(infoPath)
+	if err != nil {
+		return false, &fsops.Error{Op: "восстановление", Path: name, Err: notFound(err)}
+	}
+	// Корзина в домашней папке хранит только абсолютные пути.
+	original := filepath.Clean(item.OriginalPath)
+	if !filepath.IsAbs(original) {
+		return false, &fsops.Error{Op: "восстановление", Path: name,
+			Err: fmt.Errorf("%w: исходный путь %q не абсолютный", fsops.ErrInvalidName, item.OriginalPath)}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(original), 0755); err != nil {
+		return false, err
+	}
+	trashedPath := filepath.Join(trashDir, "files", name)
+	if err := fsops.Move(trashedPath, original, opts); err != nil {
+		return false, err
+	}
+	if _, err := os.Lstat(trashedPath); err == nil {
+		return false, nil
+	}
+	return true, os.Remove(infoPath)
+}
+
+// Empty окончательно удаляет из корзины файлы, удалённые раньше чем
+// olderThan назад (0 — все файлы), и возвращает их количество.
+func Empty(olderThan time.Duration) (int, error) {
+	items, err := List()
+	if err != nil {
+		return 0, err
+	}
+
+	trashDir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(-olderThan)
+	removed := 0
+	for _, item := range items {
+		if olderThan > 0 && item.DeletedAt.After(deadline) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(trashDir, "files", item.Name)); err != nil {
+			return removed, err
+		}
+		if err := os.Remove(filepath.Join(trashDir, "info", item.Name+".trashinfo")); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrNotInTrash, fsops.ErrNotFound)
+	}
+	return err
+}
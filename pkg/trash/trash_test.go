@@ -0,0 +1,71 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		name string
+		// info, если задано, заменяет содержимое .trashinfo.
+		info     string
+		restore  string
+		conflict fsops.ConflictAction
+		// occupied создаёт файл на исходном месте до восстановления.
+		occupied bool
+		restored bool
+		err      error
+	}{
+		{name: "восстановление", restore: "a.txt", restored: true},
+		{name: "пропуск при конфликте", restore: "a.txt", occupied: true, conflict: fsops.ConflictSkip},
+		{name: "замена при конфликте", restore: "a.txt", occupied: true, conflict: fsops.ConflictOverwrite, restored: true},
+		{name: "выход из корзины", restore: "../info/a.txt", err: fsops.ErrInvalidName},
+		{name: "родительская папка", restore: "..", err: fsops.ErrInvalidName},
+		{name: "нет в корзине", restore: "b.txt", err: ErrNotInTrash},
+		{name: "относительный исходный путь", info: "[Trash Info]\nPath=../a.txt\n", restore: "a.txt", err: fsops.ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+			work := t.TempDir()
+			original := filepath.Join(work, "a.txt")
+			if err := os.WriteFile(original, []byte("из корзины"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := Put(original); err != nil {
+				t.Fatal(err)
+			}
+			trashDir, _ := Dir()
+			if tt.info != "" {
+				if err := os.WriteFile(filepath.Join(trashDir, "info", "a.txt.trashinfo"), []byte(tt.info), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.occupied {
+				if err := os.WriteFile(original, []byte("новый"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			opts := fsops.TransferOptions{OnConflict: func(string, string) fsops.ConflictAction { return tt.conflict }}
+			restored, err := Restore(tt.restore, opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if restored != tt.restored {
+				t.Errorf("restored = %v, want %v", restored, tt.restored)
+			}
+
+			_, statErr := os.Lstat(filepath.Join(trashDir, "files", "a.txt"))
+			if inTrash := statErr == nil; inTrash == tt.restored {
+				t.Errorf("файл в корзине: %v, восстановлен: %v", inTrash, tt.restored)
+			}
+		})
+	}
+}
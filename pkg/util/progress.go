@@ -0,0 +1,104 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+)
+
+// progressDelay — операции короче этого времени идут без индикатора,
+// чтобы он не мелькал на каждом маленьком файле.
+const progressDelay = 300 * time.Millisecond
+
+const progressWidth = 24
+
+// ProgressBar выводит строку вида
+//
+//	[##########--------------]  42%  120.0/285.3 МБ  310/742 файлов  35.1 МБ/с  осталось 00:04
+//
+// и перерисовывает её на месте через \r.
+type ProgressBar struct {
+	w       io.Writer
+	start   time.Time
+	drawn   bool
+	lastLen int
+}
+
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{w: w, start: time.Now()}
+}
+
+// Update подходит как значение для поля Progress в опциях fsops.
+func (b *ProgressBar) Update(p fsops.ProgressInfo) {
+	elapsed := time.Since(b.start)
+	if !b.drawn && elapsed < progressDelay {
+		return
+	}
+	b.drawn = true
+
+	line := progressLine(p, elapsed)
+	width := utf8.RuneCountInString(line)
+	padding := ""
+	if width < b.lastLen {
+		padding = strings.Repeat(" ", b.lastLen-width)
+	}
+	b.lastLen = width
+	fmt.Fprint(b.w, "\r", line, padding)
+	if p.Done {
+		fmt.Fprintln(b.w)
+	}
+}
+
+func progressLine(p fsops.ProgressInfo, elapsed time.Duration) string {
+	var parts []string
+	if p.TotalBytes > 0 {
+		done := min(float64(p.Bytes)/float64(p.TotalBytes), 1)
+		filled := int(done * progressWidth)
+		parts = append(parts,
+			"["+strings.Repeat("#", filled)+strings.Repeat("-", progressWidth-filled)+"]",
+			fmt.Sprintf("%3.0f%%", done*100),
+			fmt.Sprintf("%s/%s МБ", megabytes(p.Bytes), megabytes(p.TotalBytes)))
+	} else {
+		parts = append(parts, megabytes(p.Bytes)+" МБ")
+	}
+
+	if p.TotalFiles > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d файлов", p.Files, p.TotalFiles))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d файлов", p.Files))
+	}
+
+	rate := float64(p.Bytes) / elapsed.Seconds()
+	parts = append(parts, megabytes(int64(rate))+" МБ/с")
+	if !p.Done && rate > 0 && p.TotalBytes > p.Bytes {
+		eta := time.Duration(float64(p.TotalBytes-p.Bytes) / rate * float64(time.Second))
+		parts = append(parts, "осталось "+clock(eta))
+	}
+	return strings.Join(parts, "  ")
+}
+
+func megabytes(n int64) string {
+	return fmt.Sprintf("%.1f", float64(n)/(1<<20))
+}
+
+func clock(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// WithInterrupt возвращает контекст, который отменяется по Ctrl+C.
+// Пока он не остановлен через stop, Ctrl+C прерывает только текущую
+// операцию, а не всю программу.
+func WithInterrupt() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
@@ -2,9 +2,14 @@ package util
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+
+	"github.com/AlanMute/file-manager/pkg/fsops"
+	"github.com/AlanMute/file-manager/pkg/workspace"
+	"golang.org/x/term"
 )
 
 func Pause() {
@@ -12,11 +17,105 @@ func Pause() {
 	bufio.NewReader(os.Stdin).ReadBytes('\n')
 }
 
-func GetDocumentsPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+func Confirm(scanner *bufio.Scanner, question string) bool {
+	fmt.Print(question, " (y/n): ")
+	scanner.Scan()
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes" || answer == "д" || answer == "да"
+}
+
+// ResolvePath возвращает путь к name в рабочей папке. Если путь выходит
+// за её пределы, пользователь должен явно подтвердить операцию.
+func ResolvePath(scanner *bufio.Scanner, name string) (string, error) {
+	path, err := workspace.Path(name)
+
+	var outside *workspace.OutsideRootError
+	if errors.As(err, &outside) {
+		fmt.Printf("Путь %s выходит за пределы рабочей папки %s.\n", outside.Path, outside.Root)
+		if Confirm(scanner, "Всё равно продолжить?") {
+			return outside.Path, nil
+		}
+	}
+	return path, err
+}
+
+// AskConflict спрашивает пользователя, что делать с уже существующим
+// файлом. Ответ заглавной буквой применяется ко всем следующим конфликтам.
+func AskConflict(scanner *bufio.Scanner) func(src, dst string) fsops.ConflictAction {
+	remembered := fsops.ConflictFail
+
+	return func(src, dst string) fsops.ConflictAction {
+		if remembered != fsops.ConflictFail {
+			return remembered
+		}
+
+		for {
+			fmt.Printf("Файл %s уже существует.\n", dst)
+			fmt.Print("[o] перезаписать, [s] пропустить, [r] сохранить под новым именем, [c] отменить (O/S/R — для всех): ")
+			scanner.Scan()
+			answer := strings.TrimSpace(scanner.Text())
+
+			var action fsops.ConflictAction
+			switch strings.ToLower(answer) {
+			case "o":
+				action = fsops.ConflictOverwrite
+			case "s":
+				action = fsops.ConflictSkip
+			case "r":
+				action = fsops.ConflictRename
+			case "c":
+				return fsops.ConflictFail
+			default:
+				fmt.Println("Неверный выбор, попробуйте снова.")
+				continue
+			}
+
+			if answer != strings.ToLower(answer) {
+				remembered = action
+			}
+			return action
+		}
+	}
+}
+
+// AskPassword читает пароль без эха, если ввод идёт с терминала, и
+// обычной строкой из scanner, если нет. Приглашение выводится в stderr,
+// чтобы не смешиваться с выводом команд.
+func AskPassword(scanner *bufio.Scanner, prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err == nil {
+			return string(password)
+		}
+	}
+
+	scanner.Scan()
+	return scanner.Text()
+}
+
+// AskNewPassword спрашивает пароль для шифрования. С терминала пароль
+// вводится дважды, чтобы опечатка не сделала архив нечитаемым.
+// Пустой пароль означает «без шифрования».
+func AskNewPassword(scanner *bufio.Scanner) (string, error) {
+	password := AskPassword(scanner, "Пароль (пусто — без шифрования): ")
+	if password == "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return password, nil
+	}
+	if AskPassword(scanner, "Повторите пароль: ") != password {
+		return "", errors.New("пароли не совпадают")
+	}
+	return password, nil
+}
+
+// ColorEnabled сообщает, можно ли выводить в f цветной текст: f должен
+// быть терминалом, а цвет не отключён переменной NO_COLOR или TERM=dumb.
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
 	}
-	documentsPath := filepath.Join(homeDir, "Documents")
-	return documentsPath, nil
+	return term.IsTerminal(int(f.Fd()))
 }
@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DocumentsDir возвращает XDG_DOCUMENTS_DIR из user-dirs.dirs,
+// а если он не задан — ~/Documents.
+func DocumentsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+
+	file, err := os.Open(filepath.Join(configHome, "user-dirs.dirs"))
+	if err != nil {
+		return filepath.Join(homeDir, "Documents"), nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || key != "XDG_DOCUMENTS_DIR" {
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+		if value == "$HOME" || value == "$HOME/" {
+			// По спецификации это значит, что папка документов отключена.
+			break
+		}
+		if rest, ok := strings.CutPrefix(value, "$HOME/"); ok {
+			return filepath.Join(homeDir, rest), nil
+		}
+		if filepath.IsAbs(value) {
+			return value, nil
+		}
+	}
+
+	return filepath.Join(homeDir, "Documents"), nil
+}
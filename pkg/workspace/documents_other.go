@@ -0,0 +1,16 @@
+//go:build !linux
+
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func DocumentsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Documents"), nil
+}
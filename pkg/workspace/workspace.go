@@ -0,0 +1,273 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvRoot задаёт рабочую папку, если она не указана флагом.
+const EnvRoot = "FILE_MANAGER_ROOT"
+
+var (
+	ErrNotDir      = errors.New("не является папкой")
+	ErrOutsideRoot = errors.New("путь выходит за пределы рабочей папки")
+)
+
+// OutsideRootError возвращается Path, если путь после раскрытия
+// символических ссылок оказывается вне рабочей папки.
+type OutsideRootError struct {
+	Path string
+	Root string
+}
+
+func (e *OutsideRootError) Error() string {
+	return fmt.Sprintf("%s: %v %s", e.Path, ErrOutsideRoot, e.Root)
+}
+
+func (e *OutsideRootError) Is(target error) bool {
+	return target == ErrOutsideRoot
+}
+
+var (
+	root string
+	cwd  string
+)
+
+type Config struct {
+	Root string `json:"root,omitempty"`
+}
+
+// Init выбирает рабочую папку в порядке приоритета: значение флага,
+// переменная окружения FILE_MANAGER_ROOT, файл конфигурации и папка
+// документов пользователя.
+func Init(flagRoot string) error {
+	if flagRoot != "" {
+		return SetRoot(flagRoot)
+	}
+	if envRoot := os.Getenv(EnvRoot); envRoot != "" {
+		return SetRoot(envRoot)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Root != "" {
+		return SetRoot(cfg.Root)
+	}
+
+	documentsPath, err := DocumentsDir()
+	if err != nil {
+		return err
+	}
+	root, cwd = documentsPath, ""
+	return nil
+}
+
+func Root() (string, error) {
+	if root == "" {
+		if err := Init(""); err != nil {
+			return "", err
+		}
+	}
+	return root, nil
+}
+
+func SetRoot(path string) error {
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %w", path, ErrNotDir)
+	}
+
+	root, cwd = path, ""
+	return nil
+}
+
+// Dir возвращает текущую папку, относительно которой разрешаются имена.
+// Изначально это сама рабочая папка.
+func Dir() (string, error) {
+	if cwd != "" {
+		return cwd, nil
+	}
+	return Root()
+}
+
+// Chdir делает текущей папку name. Выйти за пределы рабочей папки нельзя.
+func Chdir(name string) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %w", path, ErrNotDir)
+	}
+
+	cwd = path
+	return nil
+}
+
+// Rel возвращает путь относительно рабочей папки для вывода пользователю.
+func Rel(path string) string {
+	rootPath, err := Root()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(rootPath, path)
+	if err != nil || !within(rootPath, path) {
+		return path
+	}
+	return rel
+}
+
+// Path возвращает путь к файлу name внутри рабочей папки. Имя может быть
+// абсолютным или относительным текущей папки (см. Dir); путь очищается от "..", а символические
+// ссылки в уже существующей его части раскрываются. Если итоговый путь
+// выходит за пределы рабочей папки, возвращается *OutsideRootError.
+//
+// Последний элемент пути не раскрывается, чтобы операции над самой
+// ссылкой (например, удаление) не затрагивали файл, на который она указывает.
+func Path(name string) (string, error) {
+	rootPath, err := Root()
+	if err != nil {
+		return "", err
+	}
+	dirPath, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Clean(name)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dirPath, path)
+	}
+
+	realRoot, err := evalExisting(rootPath)
+	if err != nil {
+		return "", err
+	}
+	realPath, err := evalExisting(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(realRoot, realPath) {
+		return "", &OutsideRootError{Path: path, Root: rootPath}
+	}
+
+	if path == rootPath {
+		return rootPath, nil
+	}
+	realDir, err := evalExisting(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(realDir, filepath.Base(path)), nil
+}
+
+// evalExisting раскрывает символические ссылки в самой длинной
+// существующей части пути и дописывает к ней остаток.
+func evalExisting(path string) (string, error) {
+	rest := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(path, rest), nil
+		}
+		rest = filepath.Join(filepath.Base(path), rest)
+		path = parent
+	}
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func ConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "file-manager", "config.json"), nil
+}
+
+func LoadConfig() (Config, error) {
+	var cfg Config
+
+	configPath, err := ConfigPath()
+	if err != nil {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("ошибка в файле конфигурации %s: %w", configPath, err)
+	}
+	return cfg, nil
+}
+
+func SaveConfig(cfg Config) error {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, data, 0644)
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
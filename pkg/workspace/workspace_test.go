@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPath := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(rootPath, "sub"), filepath.Join(base, "root2"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(rootPath, "a.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(rootPath, "out")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub", filepath.Join(rootPath, "in")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// dir — текущая папка относительно рабочей, "" — сама рабочая.
+		dir  string
+		path string
+		// want — ожидаемый путь относительно рабочей папки.
+		want    string
+		outside bool
+	}{
+		{name: "рабочая папка", path: ".", want: "."},
+		{name: "рабочая папка по абсолютному пути", path: rootPath, want: "."},
+		{name: "файл", path: "a.txt", want: "a.txt"},
+		{name: ".. внутри папки", path: "sub/../a.txt", want: "a.txt"},
+		{name: "выход через ..", path: "..", outside: true},
+		{name: "выход через .. в соседнюю папку", path: "../root2", outside: true},
+		{name: "выход и возврат", path: "../root/a.txt", want: "a.txt"},
+		{name: "абсолютный путь внутри", path: filepath.Join(rootPath, "sub"), want: "sub"},
+		{name: "абсолютный путь снаружи", path: outside, outside: true},
+		{name: "соседняя папка с общим префиксом", path: filepath.Join(base, "root2"), outside: true},
+		{name: "ссылка наружу", path: "out", outside: true},
+		{name: "файл за ссылкой наружу", path: "out/secret.txt", outside: true},
+		{name: "ссылка внутрь", path: "in/new.txt", want: "sub/new.txt"},
+		{name: "несуществующий хвост", path: "new/dir/file.txt", want: "new/dir/file.txt"},
+		{name: "относительно текущей папки", dir: "sub", path: "../a.txt", want: "a.txt"},
+		{name: "выход из текущей папки", dir: "sub", path: "../../root2", outside: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetRoot(rootPath); err != nil {
+				t.Fatal(err)
+			}
+			if tt.dir != "" {
+				if err := Chdir(tt.dir); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Path(tt.path)
+			if tt.outside {
+				if !errors.Is(err, ErrOutsideRoot) {
+					t.Fatalf("Path(%q) = %q, %v, want ErrOutsideRoot", tt.path, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Path(%q): %v", tt.path, err)
+			}
+			if want := filepath.Join(rootPath, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("Path(%q) = %q, want %q", tt.path, got, want)
+			}
+		})
+	}
+}